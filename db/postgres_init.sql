@@ -40,11 +40,35 @@ CREATE TABLE quizzes (
  author_id INT REFERENCES users(id) ON DELETE SET NULL, -- Идентификатор автора опроса
  title VARCHAR(255) NOT NULL, -- Название опроса
  description TEXT, -- Описание опроса
+ version INT DEFAULT 1, -- Текущая версия опроса
+ time_limit_seconds INT CHECK (time_limit_seconds > 0), -- Ограничение времени на попытку, NULL если без ограничения
+ max_attempts INT CHECK (max_attempts > 0), -- Максимальное количество попыток, NULL если без ограничения
+ cooldown_seconds INT CHECK (cooldown_seconds > 0), -- Перерыв между попытками, NULL если без перерыва
+ score_policy VARCHAR(20) NOT NULL DEFAULT 'last' CHECK (score_policy IN ('best', 'last', 'average', 'first')), -- Какая попытка определяет итоговый результат
+ shuffle_questions BOOLEAN NOT NULL DEFAULT FALSE, -- Перемешивать ли вопросы в каждой попытке
+ shuffle_choices BOOLEAN NOT NULL DEFAULT FALSE, -- Перемешивать ли варианты ответов в каждой попытке
+ status VARCHAR(20) NOT NULL DEFAULT 'published' CHECK (status IN ('draft', 'published', 'closed', 'archived')), -- Состояние опроса (черновик/опубликован/закрыт/в архиве)
+ opens_at TIMESTAMP, -- Время начала приема попыток (черновик публикуется в это время), NULL если без ограничения
+ closes_at TIMESTAMP, -- Время окончания приема попыток, NULL если без ограничения
+ reveal_policy VARCHAR(20) NOT NULL DEFAULT 'immediately' CHECK (reveal_policy IN ('immediately', 'after_close', 'after_last_attempt', 'correctness', 'never')), -- Когда участникам показываются правильные ответы
+ pass_mark FLOAT CHECK (pass_mark > 0 AND pass_mark <= 1), -- Доля баллов, необходимая для прохождения опроса, NULL если без порога
  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Время создания кортежа
- updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP -- Время обновления информации
+ updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Время обновления информации
+ CHECK (opens_at IS NULL OR closes_at IS NULL OR opens_at < closes_at)
 );
 
 CREATE INDEX idx_quizzes_author_id on quizzes(author_id);
+-- Опросы, состояние которых меняется по расписанию
+CREATE INDEX idx_quizzes_scheduled on quizzes(status) WHERE opens_at IS NOT NULL OR closes_at IS NOT NULL;
+
+CREATE TABLE quiz_versions (
+ quiz_id INT REFERENCES quizzes(id) ON DELETE CASCADE, -- Идентификатор опроса
+ version INT NOT NULL, -- Номер версии опроса
+ title VARCHAR(255) NOT NULL, -- Название опроса в данной версии
+ description TEXT, -- Описание опроса в данной версии
+ created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Время создания версии
+ PRIMARY KEY (quiz_id, version)
+);
 
 CREATE TABLE quiz_categories (
  quiz_id INT REFERENCES quizzes(id) ON DELETE CASCADE, -- Идентификатор опроса
@@ -52,18 +76,77 @@ CREATE TABLE quiz_categories (
  PRIMARY KEY (quiz_id, category_id)
 );
 
+CREATE TABLE question_banks (
+ id SERIAL PRIMARY KEY, -- Идентификатор банка вопросов
+ author_id INT REFERENCES users(id) ON DELETE SET NULL, -- Идентификатор автора банка
+ title VARCHAR(255) NOT NULL, -- Название банка
+ description TEXT, -- Описание банка
+ is_shared BOOLEAN DEFAULT FALSE, -- Могут ли другие авторы выбирать вопросы из банка
+ version INT DEFAULT 1, -- Текущая версия банка, из неё выбираются вопросы
+ created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Время создания банка
+ updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP -- Время обновления банка
+);
+
 CREATE TABLE questions (
  id SERIAL PRIMARY KEY, -- Идентификатор вопроса
- quiz_id INT REFERENCES quizzes(id) ON DELETE CASCADE, -- Идентификатор опроса
+ quiz_id INT REFERENCES quizzes(id) ON DELETE CASCADE, -- Идентификатор опроса, NULL для вопроса из банка
+ bank_id INT REFERENCES question_banks(id) ON DELETE CASCADE, -- Идентификатор банка, NULL для вопроса опроса
+ version INT DEFAULT 1, -- Версия опроса или банка, к которой относится вопрос
+ tag VARCHAR(100), -- Метка вопроса (сложность или тема), по которой вопросы выбираются из банка
+ category_id INT REFERENCES categories(id) ON DELETE SET NULL, -- Категория вопроса, по которой вопросы выбираются из банка
  question_text TEXT NOT NULL, -- Текст вопроса
- question_type VARCHAR(50) CHECK (question_type IN ('choice', 'text')) -- Тип вопроса (выбор ответа/пользовательский ввод)
+ question_type VARCHAR(50) CONSTRAINT questions_question_type_check CHECK (question_type IN ('choice', 'multi', 'text', 'numeric', 'ordering', 'matching', 'cloze')), -- Тип вопроса (выбор ответа/выбор нескольких ответов/пользовательский ввод/числовой ответ/упорядочивание/сопоставление/заполнение пропусков), список типов обновляется приложением при запуске
+ scoring_mode VARCHAR(50) CHECK (scoring_mode IN ('all_or_nothing', 'partial', 'exact', 'position', 'kendall', 'manual')), -- Способ начисления баллов за вопрос с частичным зачётом или ручной проверкой
+ points FLOAT DEFAULT 1 CHECK (points > 0), -- Количество баллов за правильный ответ
+ negative_points FLOAT DEFAULT 0 CHECK (negative_points >= 0), -- Штраф за неправильный ответ на вопрос с выбором ответа
+ answer_formula TEXT -- Формула правильного ответа от переменных вопроса, NULL для вопроса без переменных
 );
 
 CREATE INDEX idx_questions_quiz_id on questions(quiz_id);
+CREATE INDEX idx_questions_quiz_id_version on questions(quiz_id, version);
+CREATE INDEX idx_questions_bank_id_version on questions(bank_id, version);
+
+CREATE TABLE quiz_draw_rules (
+ id SERIAL PRIMARY KEY, -- Идентификатор правила
+ quiz_id INT REFERENCES quizzes(id) ON DELETE CASCADE, -- Идентификатор опроса
+ version INT NOT NULL, -- Версия опроса, к которой относится правило
+ bank_id INT REFERENCES question_banks(id) ON DELETE CASCADE, -- Банк, из которого выбираются вопросы
+ tag VARCHAR(100), -- Выбирать только вопросы с этой меткой, NULL для любых
+ category_id INT REFERENCES categories(id) ON DELETE SET NULL, -- Выбирать только вопросы этой категории, NULL для любых
+ question_count INT NOT NULL CHECK (question_count > 0), -- Количество случайных вопросов в каждой попытке
+ position INT NOT NULL -- Порядок правила в опросе
+);
+
+CREATE INDEX idx_quiz_draw_rules_quiz_id_version on quiz_draw_rules(quiz_id, version);
+
+CREATE TABLE question_variables (
+ id SERIAL PRIMARY KEY, -- Идентификатор переменной
+ question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
+ name VARCHAR(50) NOT NULL, -- Имя переменной, подставляется в текст вопроса как {name}
+ min_value DOUBLE PRECISION NOT NULL, -- Наименьшее значение
+ max_value DOUBLE PRECISION NOT NULL, -- Наибольшее значение
+ step DOUBLE PRECISION DEFAULT 1 CHECK (step > 0), -- Шаг между возможными значениями
+ CHECK (min_value <= max_value),
+ UNIQUE(question_id, name)
+);
 
 CREATE TABLE text_question_answers (
+    id SERIAL PRIMARY KEY, -- Идентификатор правильного ответа
+    question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
+    blank_number INT DEFAULT 0, -- Номер пропуска (0 для текстовых вопросов)
+    right_answer TEXT NOT NULL, -- Правильный ответ (или регулярное выражение)
+    match_mode VARCHAR(50) DEFAULT 'exact' CHECK (match_mode IN ('exact', 'normalized', 'levenshtein', 'regex')), -- Способ сравнения с ответом пользователя
+    max_distance INT DEFAULT 0 -- Допустимое расстояние Левенштейна
+);
+
+CREATE INDEX idx_text_question_answers_question_id on text_question_answers(question_id);
+
+CREATE TABLE numeric_question_answers (
     question_id INT PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
-    right_answer TEXT NOT NULL -- Правильный ответ
+    target_value DOUBLE PRECISION NOT NULL, -- Правильное значение
+    tolerance DOUBLE PRECISION DEFAULT 0, -- Допустимая погрешность
+    tolerance_mode VARCHAR(50) DEFAULT 'absolute' CHECK (tolerance_mode IN ('absolute', 'relative')), -- Тип погрешности (абсолютная/относительная)
+    units TEXT[] DEFAULT '{}' -- Допустимые единицы измерения
 );
 
 CREATE TABLE choices (
@@ -75,25 +158,154 @@ CREATE TABLE choices (
 
 CREATE INDEX idx_choices_question_id on choices(question_id);
 
+CREATE TABLE question_explanations (
+ question_id INT PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
+ explanation TEXT NOT NULL -- Пояснение к правильному ответу
+);
+
+CREATE TABLE choice_feedback (
+ choice_id INT PRIMARY KEY REFERENCES choices(id) ON DELETE CASCADE, -- Идентификатор варианта ответа
+ feedback TEXT NOT NULL -- Комментарий, показываемый выбравшему этот вариант
+);
+
+CREATE TABLE cloze_blank_options (
+ id SERIAL PRIMARY KEY, -- Идентификатор варианта для пропуска
+ question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
+ blank_number INT NOT NULL, -- Номер пропуска
+ option_text VARCHAR(255) NOT NULL, -- Текст варианта
+ is_correct BOOLEAN DEFAULT FALSE -- Правильность варианта
+);
+
+CREATE INDEX idx_cloze_blank_options_question_id on cloze_blank_options(question_id);
+
+CREATE TABLE ordering_items (
+ id SERIAL PRIMARY KEY, -- Идентификатор элемента последовательности
+ question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
+ item_text VARCHAR(255) NOT NULL, -- Текст элемента
+ position INT NOT NULL -- Позиция элемента в правильной последовательности
+);
+
+CREATE INDEX idx_ordering_items_question_id on ordering_items(question_id);
+
+CREATE TABLE matching_pairs (
+ id SERIAL PRIMARY KEY, -- Идентификатор пары
+ question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
+ left_text VARCHAR(255) NOT NULL, -- Текст левого элемента
+ right_text VARCHAR(255) NOT NULL, -- Текст правого элемента, соответствующего левому
+ position INT NOT NULL -- Порядок пары в вопросе
+);
+
+CREATE INDEX idx_matching_pairs_question_id on matching_pairs(question_id);
+
 CREATE TABLE choice_question_answers (
     question_id INT PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
     right_choice_id INT REFERENCES choices(id) ON DELETE CASCADE  -- Идентификатор правильного варианта ответа
 );
 
-CREATE TABLE choice_answers (
+CREATE TABLE quiz_participation_times (
+ id SERIAL PRIMARY KEY, -- Идентификатор участия в опросе
+ participation_number INT DEFAULT 0, -- Номер попытки
  user_id INT REFERENCES users(id) ON DELETE CASCADE, -- Идентификатор пользователя
+ quiz_id INT REFERENCES quizzes(id) ON DELETE CASCADE, -- Идентификатор опроса
+ quiz_version INT DEFAULT 1, -- Версия опроса, на которую отвечал пользователь
+ started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Время старта участия в опросе
+ finished_at TIMESTAMP, -- Время конца участия в опросе
+ score FLOAT, -- Процент выполнения опроса в этой попытке, NULL пока попытка не оценена
+ points FLOAT, -- Набранные в этой попытке баллы
+ max_points FLOAT, -- Максимально возможное количество баллов в этой попытке
+ question_seed BIGINT, -- Зерно перестановки вопросов, NULL если вопросы не перемешиваются
+ choice_seed BIGINT, -- Зерно перестановки вариантов ответов, NULL если варианты не перемешиваются
+ passed BOOLEAN, -- Пройден ли опрос в этой попытке, NULL если у опроса нет порога или попытка не оценена
+ UNIQUE(user_id, quiz_id, participation_number)
+);
+
+CREATE INDEX idx_quiz_participation_times_quiz_id on quiz_participation_times(quiz_id);
+CREATE INDEX idx_quiz_participation_times_open on quiz_participation_times(started_at) WHERE finished_at IS NULL;
+-- У пользователя может быть только одна незавершенная попытка на каждый опрос
+CREATE UNIQUE INDEX idx_quiz_participation_times_open_attempt on quiz_participation_times(user_id, quiz_id) WHERE finished_at IS NULL;
+
+CREATE TABLE attempt_questions (
+ attempt_id INT REFERENCES quiz_participation_times(id) ON DELETE CASCADE, -- Идентификатор попытки
+ question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Вопрос, доставшийся в попытке
+ position INT NOT NULL, -- Порядок вопроса в попытке
+ PRIMARY KEY (attempt_id, question_id)
+);
+
+CREATE TABLE attempt_variables (
+ attempt_id INT REFERENCES quiz_participation_times(id) ON DELETE CASCADE, -- Идентификатор попытки
+ question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
+ name VARCHAR(50) NOT NULL, -- Имя переменной
+ value DOUBLE PRECISION NOT NULL, -- Значение, сгенерированное для попытки
+ PRIMARY KEY (attempt_id, question_id, name)
+);
+
+CREATE TABLE attempt_item_tokens (
+ attempt_id INT REFERENCES quiz_participation_times(id) ON DELETE CASCADE, -- Идентификатор попытки
  question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
- choice_id INT REFERENCES choices(id) ON DELETE CASCADE, -- Идентификатор выбранного варианта ответа (может быть незаданным)
+ item_id INT NOT NULL, -- Элемент последовательности или пара сопоставления
+ token INT NOT NULL, -- Случайный идентификатор, под которым элемент показан участнику
+ PRIMARY KEY (attempt_id, question_id, item_id),
+ UNIQUE (attempt_id, question_id, token)
+);
+
+CREATE TABLE choice_answers (
+ attempt_id INT REFERENCES quiz_participation_times(id) ON DELETE CASCADE, -- Идентификатор попытки
+ question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
+ choice_id INT REFERENCES choices(id) ON DELETE CASCADE, -- Идентификатор выбранного варианта ответа
  answered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Время ответа на вопрос
- PRIMARY KEY (user_id, question_id)
+ PRIMARY KEY (attempt_id, question_id, choice_id)
 );
 
 CREATE TABLE text_answers (
- user_id INT REFERENCES users(id) ON DELETE CASCADE, -- Идентификатор пользователя
+ attempt_id INT REFERENCES quiz_participation_times(id) ON DELETE CASCADE, -- Идентификатор попытки
  question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
+ blank_number INT DEFAULT 0, -- Номер пропуска (0 для текстовых вопросов)
  text_answer TEXT, -- Пользовательский текст ответа (может быть незаданным)
  answered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Время ответа на вопрос
- PRIMARY KEY (user_id, question_id)
+ PRIMARY KEY (attempt_id, question_id, blank_number)
+);
+
+CREATE TABLE ordering_answers (
+ attempt_id INT REFERENCES quiz_participation_times(id) ON DELETE CASCADE, -- Идентификатор попытки
+ question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
+ item_id INT REFERENCES ordering_items(id) ON DELETE CASCADE, -- Идентификатор элемента последовательности
+ position INT NOT NULL, -- Позиция, на которую пользователь поставил элемент
+ answered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Время ответа на вопрос
+ PRIMARY KEY (attempt_id, question_id, position)
+);
+
+CREATE TABLE matching_answers (
+ attempt_id INT REFERENCES quiz_participation_times(id) ON DELETE CASCADE, -- Идентификатор попытки
+ question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса
+ pair_id INT REFERENCES matching_pairs(id) ON DELETE CASCADE, -- Пара, левый элемент которой сопоставляется
+ matched_pair_id INT REFERENCES matching_pairs(id) ON DELETE CASCADE, -- Пара, правый элемент которой выбрал пользователь
+ answered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Время ответа на вопрос
+ PRIMARY KEY (attempt_id, question_id, pair_id)
+);
+
+CREATE TABLE manual_grades (
+ attempt_id INT REFERENCES quiz_participation_times(id) ON DELETE CASCADE, -- Идентификатор попытки
+ question_id INT REFERENCES questions(id) ON DELETE CASCADE, -- Идентификатор вопроса с ручной проверкой
+ points FLOAT CHECK (points >= 0), -- Выставленные баллы, NULL пока ответ ожидает проверки
+ feedback TEXT, -- Комментарий проверяющего
+ reviewer_id INT REFERENCES users(id) ON DELETE SET NULL, -- Идентификатор проверяющего
+ graded_at TIMESTAMP, -- Время проверки
+ PRIMARY KEY (attempt_id, question_id)
+);
+
+CREATE INDEX idx_manual_grades_pending on manual_grades(attempt_id) WHERE points IS NULL;
+
+CREATE TABLE certificates (
+ id SERIAL PRIMARY KEY, -- Идентификатор сертификата
+ code VARCHAR(32) NOT NULL UNIQUE, -- Код для проверки подлинности сертификата
+ user_id INT REFERENCES users(id) ON DELETE CASCADE, -- Идентификатор получателя
+ quiz_id INT REFERENCES quizzes(id) ON DELETE CASCADE, -- Идентификатор пройденного опроса
+ attempt_id INT REFERENCES quiz_participation_times(id) ON DELETE CASCADE, -- Попытка, в которой опрос был пройден
+ username VARCHAR(255) NOT NULL, -- Имя получателя на момент выдачи
+ quiz_title VARCHAR(255) NOT NULL, -- Название опроса на момент выдачи
+ score FLOAT NOT NULL, -- Процент выполнения опроса в попытке
+ issued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Время выдачи
+ UNIQUE(user_id, quiz_id) -- Сертификат выдается за первое прохождение опроса
 );
 
 CREATE TABLE user_quiz_participations (
@@ -107,28 +319,20 @@ CREATE TABLE user_quiz_scores (
  user_id INT REFERENCES users(id) ON DELETE CASCADE, -- Идентификатор пользователя
  quiz_id INT REFERENCES quizzes(id) ON DELETE CASCADE, -- Идентификатор опроса
  score FLOAT, -- Процент выполнения опроса
+ points FLOAT DEFAULT 0, -- Набранные баллы
+ max_points FLOAT DEFAULT 0, -- Максимально возможное количество баллов
  last_update_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Последнее время обновления (необходимо для кэша)
+ provisional BOOLEAN NOT NULL DEFAULT FALSE, -- Результат предварительный, пока не проверены все ответы с ручной проверкой
  PRIMARY KEY (user_id, quiz_id)
 );
 
 CREATE INDEX idx_user_quiz_scores_quiz_id on user_quiz_scores(quiz_id);
 
-CREATE TABLE quiz_participation_times (
- id SERIAL PRIMARY KEY, -- Идентификатор участия в опросе
- participation_number INT DEFAULT 0, -- Номер попытки
- user_id INT REFERENCES users(id) ON DELETE CASCADE, -- Идентификатор пользователя
- quiz_id INT REFERENCES quizzes(id) ON DELETE CASCADE, -- Идентификатор опроса
- started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Время старта участия в опросе
- finished_at TIMESTAMP, -- Время конца участия в опросе
- UNIQUE(user_id, quiz_id, participation_number)
-);
-
-CREATE INDEX idx_quiz_participation_times_quiz_id on quiz_participation_times(quiz_id);
-
 CREATE TABLE quiz_statistics (
  quiz_id INT PRIMARY KEY REFERENCES quizzes(id) ON DELETE CASCADE, -- Идентификатор опроса
  total_attempts INT DEFAULT 0, -- Количество попыток
  average_score FLOAT, -- Средний процент выполнения опроса
+ pass_rate FLOAT, -- Доля пользователей, прошедших опрос, NULL если у опроса нет порога
  average_completion_time INTERVAL, -- Среднее время выполнения опроса
  last_update_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP -- Последнее время обновления (необходимо для кэша)
 );
@@ -191,6 +395,9 @@ VALUES
   (4, 'Music Quiz', 'Questions about music genres.'),
   (5, 'Geography Quiz', 'World geography.');
 
+INSERT INTO quiz_versions (quiz_id, version, title, description)
+SELECT id, version, title, description FROM quizzes;
+
 INSERT INTO quiz_categories (quiz_id, category_id)
 VALUES
   (1, 1),
@@ -212,18 +419,45 @@ VALUES
   (4, 'Who was the first president of the USA?', 'text'),
   (5, 'Who wrote "1984"?', 'text'),
   (6, 'Which artist painted the Mona Lisa?', 'choice'),
-  (7, 'What is the speed of light?', 'text'),
+  (7, 'What is the speed of light?', 'numeric'),
   (8, 'Which country hosted the 2016 Olympics?', 'choice'),
   (9, 'Name a genre of classical music.', 'text'),
   (10, 'What is the largest continent?', 'choice');
 
-INSERT INTO text_question_answers (question_id, right_answer)
+INSERT INTO questions (quiz_id, question_text, question_type, scoring_mode)
+VALUES
+  (7, 'Which of these are programming languages?', 'multi', 'partial'),
+  (4, 'Put these events in chronological order.', 'ordering', 'kendall'),
+  (6, 'Match the artists with their paintings.', 'matching', NULL),
+  (2, 'Water boils at {{1}} degrees Celsius and freezes at {{2}} degrees Celsius.', 'cloze', NULL);
+
+INSERT INTO questions (quiz_id, question_text, question_type, answer_formula)
 VALUES
-  (2, 'Water'),
-  (4, 'George Washington'),
-  (5, 'George Orwell'),
-  (7, '299792458 m/s'),
-  (9, 'Symphony');
+  (3, 'Solve: {a} + {b}', 'numeric', 'a + b');
+
+INSERT INTO question_variables (question_id, name, min_value, max_value, step)
+VALUES
+  (15, 'a', 1, 50, 1),
+  (15, 'b', 1, 50, 1);
+
+INSERT INTO text_question_answers (question_id, right_answer, match_mode, max_distance)
+VALUES
+  (2, 'Water', 'normalized', 0),
+  (4, 'George Washington', 'levenshtein', 2),
+  (4, 'Washington', 'normalized', 0),
+  (5, 'George Orwell', 'levenshtein', 2),
+  (5, 'Eric Arthur Blair', 'normalized', 0),
+  (9, 'Symphony', 'normalized', 0),
+  (9, '(?i)\s*(sonata|concerto|opera|baroque)\s*', 'regex', 0);
+
+INSERT INTO text_question_answers (question_id, blank_number, right_answer, match_mode, max_distance)
+VALUES
+  (14, 1, '100', 'normalized', 0);
+
+INSERT INTO numeric_question_answers (question_id, target_value, tolerance, tolerance_mode, units)
+VALUES
+  (7, 299792458, 0.001, 'relative', '{"m/s"}'),
+  (15, 0, 0, 'absolute', '{}');
 
 INSERT INTO choices (question_id, choice_text, is_correct)
 VALUES
@@ -236,7 +470,39 @@ VALUES
   (8, 'Brazil', TRUE),
   (8, 'China', FALSE),
   (10, 'Asia', TRUE),
-  (10, 'Africa', FALSE);
+  (10, 'Africa', FALSE),
+  (11, 'Go', TRUE),
+  (11, 'Python', TRUE),
+  (11, 'HTML', FALSE),
+  (11, 'Photoshop', FALSE);
+
+INSERT INTO question_explanations (question_id, explanation)
+VALUES
+  (1, 'Paris has been the capital of France since the Middle Ages.');
+
+INSERT INTO choice_feedback (choice_id, feedback)
+VALUES
+  (2, 'London is the capital of the UK, not France.');
+
+INSERT INTO cloze_blank_options (question_id, blank_number, option_text, is_correct)
+VALUES
+  (14, 2, '0', TRUE),
+  (14, 2, '32', FALSE),
+  (14, 2, '-10', FALSE);
+
+INSERT INTO ordering_items (question_id, item_text, position)
+VALUES
+  (12, 'Fall of the Western Roman Empire', 1),
+  (12, 'Discovery of America by Columbus', 2),
+  (12, 'French Revolution', 3),
+  (12, 'First Moon landing', 4);
+
+INSERT INTO matching_pairs (question_id, left_text, right_text, position)
+VALUES
+  (13, 'Leonardo da Vinci', 'The Last Supper', 1),
+  (13, 'Vincent van Gogh', 'The Starry Night', 2),
+  (13, 'Pablo Picasso', 'Guernica', 3),
+  (13, 'Salvador Dali', 'The Persistence of Memory', 4);
 
 INSERT INTO choice_question_answers (question_id, right_choice_id)
 VALUES
@@ -246,21 +512,38 @@ VALUES
   (8, 7),
   (10, 9);
 
-INSERT INTO choice_answers (user_id, question_id, choice_id)
+INSERT INTO quiz_participation_times (participation_number, user_id, quiz_id, started_at, finished_at)
+VALUES
+  (1, 1, 1, CURRENT_TIMESTAMP - INTERVAL '30 minutes', CURRENT_TIMESTAMP),
+  (1, 2, 2, CURRENT_TIMESTAMP - INTERVAL '45 minutes', CURRENT_TIMESTAMP),
+  (1, 3, 3, CURRENT_TIMESTAMP - INTERVAL '25 minutes', CURRENT_TIMESTAMP),
+  (1, 4, 4, CURRENT_TIMESTAMP - INTERVAL '40 minutes', CURRENT_TIMESTAMP),
+  (1, 5, 5, CURRENT_TIMESTAMP - INTERVAL '35 minutes', CURRENT_TIMESTAMP),
+  (1, 1, 2, CURRENT_TIMESTAMP - INTERVAL '20 minutes', CURRENT_TIMESTAMP),
+  (1, 2, 3, CURRENT_TIMESTAMP - INTERVAL '20 minutes', CURRENT_TIMESTAMP),
+  (1, 2, 4, CURRENT_TIMESTAMP - INTERVAL '15 minutes', CURRENT_TIMESTAMP),
+  (1, 3, 5, CURRENT_TIMESTAMP - INTERVAL '20 minutes', CURRENT_TIMESTAMP),
+  (1, 3, 6, CURRENT_TIMESTAMP - INTERVAL '15 minutes', CURRENT_TIMESTAMP),
+  (1, 4, 7, CURRENT_TIMESTAMP - INTERVAL '20 minutes', CURRENT_TIMESTAMP),
+  (1, 4, 8, CURRENT_TIMESTAMP - INTERVAL '15 minutes', CURRENT_TIMESTAMP),
+  (1, 5, 9, CURRENT_TIMESTAMP - INTERVAL '20 minutes', CURRENT_TIMESTAMP),
+  (1, 5, 10, CURRENT_TIMESTAMP - INTERVAL '15 minutes', CURRENT_TIMESTAMP);
+
+INSERT INTO choice_answers (attempt_id, question_id, choice_id)
 VALUES
   (1, 1, 1),
-  (2, 3, 3),
-  (3, 6, 5),
-  (4, 8, 7),
-  (5, 10, 9);
+  (7, 3, 3),
+  (10, 6, 5),
+  (12, 8, 7),
+  (14, 10, 9);
 
-INSERT INTO text_answers (user_id, question_id, text_answer)
+INSERT INTO text_answers (attempt_id, question_id, text_answer)
 VALUES
-  (1, 2, 'Water'),
-  (2, 4, 'George Washington'),
-  (3, 5, 'George Orwell'),
-  (4, 7, '299792458 m/s'),
-  (5, 9, 'Symphony');
+  (6, 2, 'Water'),
+  (8, 4, 'George Washington'),
+  (9, 5, 'George Orwell'),
+  (11, 7, '299792458 m/s'),
+  (13, 9, 'Symphony');
 
 INSERT INTO user_quiz_participations (user_id, quiz_id, participation_count)
 VALUES
@@ -278,13 +561,6 @@ VALUES
   (4, 4, 88.5),
   (5, 5, 92.0);
 
-INSERT INTO quiz_participation_times (participation_number, user_id, quiz_id, started_at, finished_at)
-VALUES
-  (1, 1, 1, CURRENT_TIMESTAMP - INTERVAL '30 minutes', CURRENT_TIMESTAMP),
-  (1, 2, 2, CURRENT_TIMESTAMP - INTERVAL '45 minutes', CURRENT_TIMESTAMP),
-  (1, 3, 3, CURRENT_TIMESTAMP - INTERVAL '25 minutes', CURRENT_TIMESTAMP),
-  (1, 4, 4, CURRENT_TIMESTAMP - INTERVAL '40 minutes', CURRENT_TIMESTAMP),
-  (1, 5, 5, CURRENT_TIMESTAMP - INTERVAL '35 minutes', CURRENT_TIMESTAMP);
 
 INSERT INTO quiz_statistics (quiz_id, total_attempts, average_score, average_completion_time)
 VALUES
@@ -337,23 +613,57 @@ LANGUAGE plpgsql AS $$
 DECLARE
   this_total_attempts INT;
   this_average_score FLOAT;
+  this_pass_rate FLOAT;
   this_average_time INTERVAL;
+  this_score_policy VARCHAR(20);
+  this_pass_mark FLOAT;
 BEGIN
   SELECT COUNT(*), AVG(finished_at - started_at)
   INTO this_total_attempts, this_average_time
   FROM quiz_participation_times
   WHERE quiz_participation_times.quiz_id = this_quiz_id AND quiz_participation_times.finished_at IS NOT NULL;
 
-  SELECT AVG(score)
-  INTO this_average_score
-  FROM user_quiz_scores
-  WHERE user_quiz_scores.quiz_id = this_quiz_id;
-
-  INSERT INTO quiz_statistics (quiz_id, total_attempts, average_score, average_completion_time, last_update_time)
-  VALUES (this_quiz_id, this_total_attempts, this_average_score, this_average_time, CURRENT_TIMESTAMP)
+  SELECT score_policy, pass_mark
+  INTO this_score_policy, this_pass_mark
+  FROM quizzes
+  WHERE quizzes.id = this_quiz_id;
+
+  -- Итоговый результат каждого пользователя выбирается по политике опроса,
+  -- пользователь прошел опрос, если итоговый результат не ниже порога
+  -- (с допуском на погрешность хранения результата)
+  SELECT AVG(user_score), AVG(CASE WHEN user_score >= this_pass_mark - 1e-6 THEN 1.0 ELSE 0.0 END)
+  INTO this_average_score, this_pass_rate
+  FROM (
+    SELECT CASE this_score_policy
+      WHEN 'best' THEN MAX(score)
+      WHEN 'average' THEN AVG(score)
+      WHEN 'first' THEN (ARRAY_AGG(score ORDER BY participation_number))[1]
+      ELSE (ARRAY_AGG(score ORDER BY participation_number DESC))[1]
+    END AS user_score
+    FROM quiz_participation_times
+    WHERE quiz_participation_times.quiz_id = this_quiz_id
+    AND finished_at IS NOT NULL AND score IS NOT NULL
+    GROUP BY user_id
+  ) AS user_scores;
+
+  -- Попытки без сохраненного результата учитываются по таблице результатов
+  IF this_average_score IS NULL THEN
+    SELECT AVG(score)
+    INTO this_average_score
+    FROM user_quiz_scores
+    WHERE user_quiz_scores.quiz_id = this_quiz_id;
+  END IF;
+
+  IF this_pass_mark IS NULL THEN
+    this_pass_rate := NULL;
+  END IF;
+
+  INSERT INTO quiz_statistics (quiz_id, total_attempts, average_score, pass_rate, average_completion_time, last_update_time)
+  VALUES (this_quiz_id, this_total_attempts, this_average_score, this_pass_rate, this_average_time, CURRENT_TIMESTAMP)
   ON CONFLICT (quiz_id) DO UPDATE
   SET total_attempts = EXCLUDED.total_attempts,
       average_score = EXCLUDED.average_score,
+      pass_rate = EXCLUDED.pass_rate,
       average_completion_time = EXCLUDED.average_completion_time,
       last_update_time = CURRENT_TIMESTAMP;
 END;
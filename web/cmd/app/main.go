@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"html/template"
+	"time"
 
 	"quiz_platform/internal/infrastructure"
 	"quiz_platform/internal/middleware"
@@ -64,6 +66,21 @@ func main() {
 	repository.QuizRepositoryInstance =
 		infrastructure.NewSqlQuizRepository(sqlProvider)
 
+	// Sync question types with the database
+	err = repository.QuizRepositoryInstance.
+		SetQuestionTypes(context.Background(), quiz.QuestionTypeNames())
+	if err != nil {
+		panic(fmt.Sprintf("cannot sync question types: %v", err.Error()))
+	}
+
+	// Finalize abandoned attempts in the background
+	quiz.StartAttemptSweeper(
+		time.Duration(config.GlobalConfig.App.AttemptSweepSeconds) * time.Second)
+
+	// Open and close quizzes by their schedule
+	quiz.StartQuizScheduler(
+		time.Duration(config.GlobalConfig.App.QuizScheduleSeconds) * time.Second)
+
 	r := gin.Default()
 
 	// Set funcs
@@ -113,10 +130,31 @@ func main() {
 	r.GET("/quiz", middleware.RequirePermissionMiddleware(0), quiz.QuizIndexGetHandler)
 	r.GET("/quiz/create", middleware.RequirePermissionMiddleware(0), quiz.QuizCreateFormGetHandler)
 	r.POST("/quiz/create", middleware.RequirePermissionMiddleware(0), quiz.QuizCreatePostHandler)
+	r.GET("/quiz/:id/edit", middleware.RequirePermissionMiddleware(0), quiz.QuizEditFormGetHandler)
+	r.POST("/quiz/:id/edit", middleware.RequirePermissionMiddleware(0), quiz.QuizEditPostHandler)
 	r.GET("/quiz/:id/participate", middleware.RequirePermissionMiddleware(0), quiz.QuizParticipationFormGetHandler)
 	r.POST("/quiz/participate", middleware.RequirePermissionMiddleware(0), quiz.QuizParticipationPostHandler)
+	r.POST("/quiz/participate/save", middleware.RequirePermissionMiddleware(0), quiz.QuizParticipationSavePostHandler)
 	r.POST("/quiz/:id/delete", middleware.RequirePermissionMiddleware(models.MANAGE_QUIZZES_PERM), quiz.QuizDeletePostHandler)
 	r.GET("/quiz/:id/result", middleware.RequirePermissionMiddleware(0), quiz.QuizResultGetHandler)
+	r.GET("/quiz/:id/attempts", middleware.RequirePermissionMiddleware(0), quiz.QuizAttemptsGetHandler)
+	r.POST("/quiz/:id/regrade", middleware.RequirePermissionMiddleware(0), quiz.QuizRegradePostHandler)
+	r.POST("/quiz/:id/questions/:question/key", middleware.RequirePermissionMiddleware(0), quiz.QuestionKeyPostHandler)
+
+	// Question banks
+	r.GET("/banks", middleware.RequirePermissionMiddleware(0), quiz.BankIndexGetHandler)
+	r.GET("/banks/create", middleware.RequirePermissionMiddleware(0), quiz.BankCreateFormGetHandler)
+	r.POST("/banks/create", middleware.RequirePermissionMiddleware(0), quiz.BankCreatePostHandler)
+	r.GET("/banks/:id/edit", middleware.RequirePermissionMiddleware(0), quiz.BankEditFormGetHandler)
+	r.POST("/banks/:id/edit", middleware.RequirePermissionMiddleware(0), quiz.BankEditPostHandler)
+
+	// Certificates
+	r.GET("/certificates", middleware.RequirePermissionMiddleware(0), quiz.CertificateIndexGetHandler)
+	r.GET("/certificates/:code", quiz.CertificateGetHandler)
+
+	// Manual grading
+	r.GET("/reviews", middleware.RequirePermissionMiddleware(0), quiz.ReviewIndexGetHandler)
+	r.POST("/reviews/:attempt/:question", middleware.RequirePermissionMiddleware(0), quiz.ReviewPostHandler)
 
 	// Run server
 	r.Run(fmt.Sprintf(":%d", config.GlobalConfig.App.Port))
@@ -0,0 +1,63 @@
+// Grades the stored answers of finished attempts again with the current
+// answer keys, for example after a wrong right answer was fixed:
+//
+//	go run ./cmd/regrade -quiz 3
+//	go run ./cmd/regrade -question 15
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"quiz_platform/internal/database"
+	"quiz_platform/internal/handler/quiz"
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/infrastructure"
+	"quiz_platform/internal/misc/config"
+	"quiz_platform/internal/misc/transaction"
+)
+
+func main() {
+	quizId := flag.Int("quiz", 0, "quiz to regrade, 0 for every quiz with the question")
+	questionId := flag.Int("question", 0, "question to regrade, 0 for all questions of the quiz")
+	configPath := flag.String("config", "./config/config.json", "path to the config")
+	flag.Parse()
+	if *quizId == 0 && *questionId == 0 {
+		panic("either -quiz or -question has to be set")
+	}
+
+	// Init config
+	err := config.ReadGlobalConfig(*configPath)
+	if err != nil {
+		panic(fmt.Sprintf("cannot read config: %v", err.Error()))
+	}
+
+	// Init database provider
+	sqlProvider :=
+		database.NewPostgresSqlDatabaseProvider()
+
+	// Init transaction manager
+	repository.TransactionManager =
+		transaction.NewTransactionManager(sqlProvider.GetDb())
+
+	// Init repositories
+	repository.ActionsRepositoryInstance =
+		infrastructure.NewSqlActionsRepository(sqlProvider)
+
+	repository.QuizRepositoryInstance =
+		infrastructure.NewSqlQuizRepository(sqlProvider)
+
+	var result *quiz.RegradeResult
+	ctx := context.Background()
+	err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
+		result, err = quiz.Regrade(ctx, int32(*quizId), int32(*questionId), nil)
+		return err
+	})
+	if err != nil {
+		panic(fmt.Sprintf("cannot regrade: %v", err.Error()))
+	}
+
+	fmt.Printf("regraded %d attempts, %d scores changed, %d certificates revoked\n",
+		result.Attempts, result.Changed, result.Revoked)
+}
@@ -0,0 +1,313 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/misc/apperrors"
+	"quiz_platform/internal/misc/config"
+	"quiz_platform/internal/models"
+)
+
+// Policies choosing which attempts make up the score of the user.
+const (
+	SCORE_POLICY_BEST    = "best"
+	SCORE_POLICY_LAST    = "last"
+	SCORE_POLICY_AVERAGE = "average"
+	SCORE_POLICY_FIRST   = "first"
+)
+
+// Returns nil when the quiz has no time limit.
+func attemptDeadline(quizModel *models.Quiz, partTime *models.QuizParticipationTime) *time.Time {
+	if quizModel.TimeLimitSeconds == nil {
+		return nil
+	}
+	deadline := partTime.StartedAt.Add(time.Duration(*quizModel.TimeLimitSeconds) * time.Second)
+	return &deadline
+}
+
+// Extra time accepted after the deadline to cover network delays.
+func attemptGrace() time.Duration {
+	return time.Duration(config.GlobalConfig.App.AttemptGraceSeconds) * time.Second
+}
+
+func isAttemptExpired(deadline *time.Time, now time.Time) bool {
+	return deadline != nil && now.After(deadline.Add(attemptGrace()))
+}
+
+// Returns how many attempts the user has started on the quiz.
+func attemptCount(ctx context.Context, userId int32, quizId int32) (int32, error) {
+	participations, err := repository.QuizRepositoryInstance.
+		GetUserParticipations(ctx, userId, []int32{quizId})
+	if err != nil || len(participations) == 0 {
+		return 0, err
+	}
+	return int32(participations[0].ParticipationCount), nil
+}
+
+// Opens a new attempt unless the user has used all of them or the cooldown
+// after the previous attempt has not passed yet. The attempt is counted
+// first, which also locks the count of the user until the transaction ends.
+func startAttempt(ctx context.Context, quizModel *models.Quiz, userId int32, now time.Time) error {
+	counted, err := repository.QuizRepositoryInstance.
+		IncrementParticipationCount(ctx, userId, quizModel.Id, quizModel.MaxAttempts)
+	if err != nil {
+		return err
+	}
+	if !counted {
+		return &apperrors.ErrPermissionDenied{Message: "no attempts left"}
+	}
+
+	if quizModel.CooldownSeconds != nil {
+		partTime, err := repository.QuizRepositoryInstance.
+			GetQuizParticipationTime(ctx, userId, quizModel.Id)
+		if _, ok := err.(*apperrors.ErrNotFound); !ok && err != nil {
+			return err
+		}
+		if err == nil {
+			available := partTime.FinishedAt.Add(time.Duration(*quizModel.CooldownSeconds) * time.Second)
+			if now.Before(available) {
+				return &apperrors.ErrPermissionDenied{Message: fmt.Sprintf(
+					"next attempt is available in %v", available.Sub(now).Round(time.Second))}
+			}
+		}
+	}
+
+	partTime := &models.QuizParticipationTime{
+		UserId:    userId,
+		QuizId:    quizModel.Id,
+		StartedAt: now,
+	}
+	partTime.QuestionSeed, partTime.ChoiceSeed = newShuffleSeeds(quizModel)
+	attemptId, err := repository.QuizRepositoryInstance.AddParticipationTime(ctx, partTime)
+	if err != nil {
+		return err
+	}
+	questionIds, err := drawAttemptQuestions(ctx, quizModel)
+	if err != nil {
+		return err
+	}
+	err = repository.QuizRepositoryInstance.AddAttemptQuestions(ctx, attemptId, questionIds)
+	if err != nil {
+		return err
+	}
+	return generateAttemptVariables(ctx, attemptId, questionIds)
+}
+
+// Returns the questions the attempt received. Attempts which have no
+// questions recorded get all questions of their quiz version.
+func attemptQuestions(ctx context.Context, partTime *models.QuizParticipationTime) ([]*models.Question, error) {
+	questions, err := repository.QuizRepositoryInstance.GetAttemptQuestions(ctx, partTime.Id)
+	if err != nil || len(questions) > 0 {
+		return questions, err
+	}
+	return repository.QuizRepositoryInstance.
+		GetQuizVersionQuestions(ctx, partTime.QuizId, partTime.QuizVersion)
+}
+
+// Combines the scored attempts, ordered from the latest, according to
+// the score policy of the quiz. Also returns the attempts the score is
+// taken from, none when nothing is scored yet.
+func aggregateAttemptScores(policy string, attempts []*models.QuizParticipationTime) (*models.UserQuizScore, []*models.QuizParticipationTime) {
+	scored := make([]*models.QuizParticipationTime, 0, len(attempts))
+	for _, a := range attempts {
+		if a.FinishedAt != nil && a.Score != nil {
+			scored = append(scored, a)
+		}
+	}
+	if len(scored) == 0 {
+		return nil, nil
+	}
+
+	pick := func(a *models.QuizParticipationTime) (*models.UserQuizScore, []*models.QuizParticipationTime) {
+		return &models.UserQuizScore{Score: *a.Score, Points: *a.Points, MaxPoints: *a.MaxPoints},
+			[]*models.QuizParticipationTime{a}
+	}
+	switch policy {
+	case SCORE_POLICY_FIRST:
+		return pick(scored[len(scored)-1])
+	case SCORE_POLICY_BEST:
+		best := scored[0]
+		for _, a := range scored[1:] {
+			if *a.Score > *best.Score {
+				best = a
+			}
+		}
+		return pick(best)
+	case SCORE_POLICY_AVERAGE:
+		total := &models.UserQuizScore{}
+		for _, a := range scored {
+			total.Score += *a.Score
+			total.Points += *a.Points
+			total.MaxPoints += *a.MaxPoints
+		}
+		n := float64(len(scored))
+		total.Score /= n
+		total.Points /= n
+		total.MaxPoints /= n
+		return total, scored
+	default:
+		return pick(scored[0])
+	}
+}
+
+// Recalculates the score of the user on the quiz from all attempts. The score
+// stays provisional while answers of the attempts it is taken from wait for
+// manual grading.
+func updateUserScore(ctx context.Context, quizModel *models.Quiz, userId int32, updateTime time.Time) error {
+	attempts, err := repository.QuizRepositoryInstance.
+		GetUserParticipationTimes(ctx, userId, quizModel.Id)
+	if err != nil {
+		return err
+	}
+	userScore, used := aggregateAttemptScores(quizModel.ScorePolicy, attempts)
+	if len(used) == 0 {
+		return nil
+	}
+	attemptIds := make([]int32, len(used))
+	for i, a := range used {
+		attemptIds[i] = a.Id
+	}
+	pending, err := repository.QuizRepositoryInstance.CountPendingReviews(ctx, attemptIds)
+	if err != nil {
+		return err
+	}
+	return repository.QuizRepositoryInstance.UpsertUserScore(ctx, userId, quizModel.Id,
+		float32(userScore.Score), float32(userScore.Points), float32(userScore.MaxPoints),
+		pending > 0, updateTime)
+}
+
+// Returns the unfinished attempt of the user on the quiz. The attempt stays
+// locked until the transaction ends, so that it is not finished meanwhile.
+func getOpenAttempt(ctx context.Context, userId int32, attemptId int32, quizId int32) (*models.QuizParticipationTime, error) {
+	partTime, err := repository.QuizRepositoryInstance.LockParticipationTime(ctx, attemptId)
+	if _, ok := err.(*apperrors.ErrNotFound); ok || (err == nil && partTime.UserId != userId) {
+		return nil, fmt.Errorf("no participation")
+	} else if err != nil {
+		return nil, err
+	}
+	if partTime.QuizId != quizId {
+		return nil, fmt.Errorf("invalid quiz")
+	}
+	if partTime.FinishedAt != nil {
+		return nil, fmt.Errorf("attempt already finished")
+	}
+	return partTime, nil
+}
+
+// Stores the answers given so far without closing the attempt.
+// Answers to questions outside of the attempt are ignored.
+func saveAnswers(ctx context.Context, partTime *models.QuizParticipationTime, answers map[int32]Answer) error {
+	questionModels, err := attemptQuestions(ctx, partTime)
+	if err != nil {
+		return err
+	}
+	for _, q := range questionModels {
+		answ, ok := answers[q.Id]
+		if !ok {
+			continue
+		}
+		questionType, err := getQuestionType(q.QuestionType)
+		if err != nil {
+			return err
+		}
+		if _, err := questionType.Grade(ctx, partTime.Id, q, answ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the answers saved in the attempt keyed by question id.
+func loadSavedAnswers(ctx context.Context, partTime *models.QuizParticipationTime) (map[string]Answer, error) {
+	questionModels, err := attemptQuestions(ctx, partTime)
+	if err != nil {
+		return nil, err
+	}
+	saved := make(map[string]Answer)
+	for _, q := range questionModels {
+		questionType, err := getQuestionType(q.QuestionType)
+		if err != nil {
+			return nil, err
+		}
+		answ, err := questionType.LoadAnswer(ctx, partTime.Id, q)
+		if err != nil {
+			return nil, err
+		}
+		if answ != nil {
+			saved[strconv.Itoa(int(q.Id))] = answ
+		}
+	}
+	return saved, nil
+}
+
+// Closes the attempt and stores its score. Submitted answers are stored
+// first, questions missing from answers keep the answers saved before.
+// Answers to manually graded questions are queued for review. Fails when
+// the attempt has been finished already, for example by the sweeper.
+func finishAttempt(ctx context.Context, quizModel *models.Quiz, partTime *models.QuizParticipationTime, answers map[int32]Answer, finishTime time.Time) error {
+	err := repository.QuizRepositoryInstance.UpdateParticipationTime(ctx, partTime.Id, finishTime)
+	if _, ok := err.(*apperrors.ErrNotFound); ok {
+		return fmt.Errorf("attempt already finished")
+	} else if err != nil {
+		return err
+	}
+	if err = saveAnswers(ctx, partTime, answers); err != nil {
+		return err
+	}
+	if err = queueManualGrades(ctx, partTime); err != nil {
+		return err
+	}
+	_, err = scoreAttempt(ctx, quizModel, partTime, finishTime)
+	return err
+}
+
+// Returns whether the score reaches the pass mark of the quiz, nil when the
+// quiz has no pass mark. Scores are stored with float32 precision.
+func isPassed(quizModel *models.Quiz, score float64) *bool {
+	if quizModel.PassMark == nil {
+		return nil
+	}
+	passed := score >= *quizModel.PassMark-1e-6
+	return &passed
+}
+
+// Scores the answers stored in a finished attempt and updates the score
+// of the user on the quiz. Returns the new score of the attempt.
+func scoreAttempt(ctx context.Context, quizModel *models.Quiz, partTime *models.QuizParticipationTime, updateTime time.Time) (float32, error) {
+	questionModels, err := attemptQuestions(ctx, partTime)
+	if err != nil {
+		return 0, err
+	}
+	points := float32(0)
+	maxPoints := float32(0)
+
+	for _, q := range questionModels {
+		maxPoints += float32(q.Points)
+		questionType, err := getQuestionType(q.QuestionType)
+		if err != nil {
+			return 0, err
+		}
+		credit, answered, err := questionType.
+			DescribeResult(ctx, partTime.Id, q, &AnsweredQuestion{})
+		if err != nil {
+			return 0, err
+		}
+		points += questionPoints(q, credit, answered)
+	}
+
+	score := float32(0)
+	if maxPoints > 0 && points > 0 {
+		score = points / maxPoints
+	}
+	err = repository.QuizRepositoryInstance.
+		UpdateParticipationScore(ctx, partTime.Id, score, points, maxPoints,
+			isPassed(quizModel, float64(score)))
+	if err != nil {
+		return 0, err
+	}
+	return score, updateUserScore(ctx, quizModel, partTime.UserId, updateTime)
+}
@@ -0,0 +1,390 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/middleware"
+	"quiz_platform/internal/misc/apperrors"
+	"quiz_platform/internal/models"
+	"quiz_platform/internal/utility"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Adds random questions from a bank to every attempt of the quiz. Several
+// rules on the same bank with different tags or categories stratify the draw.
+type DrawRule struct {
+	BankId     int32  `json:"bank_id" binding:"required"`
+	Tag        string `json:"tag"`
+	CategoryId int32  `json:"category_id"`
+	Count      int32  `json:"count" binding:"required,min=1"`
+}
+
+// Questions shared between quizzes through draw rules.
+type Bank struct {
+	Id          int32      `json:"-"`
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description"`
+	IsShared    bool       `json:"is_shared"`
+	Questions   []Question `json:"questions" binding:"required,dive"`
+
+	QuestionCount int    `json:"-"`
+	Tags          string `json:"-"`
+	CanEdit       bool   `json:"-"`
+}
+
+// Banks can be edited by their authors and by quiz managers.
+func canEditBank(sessionData *middleware.SessionData, bank *models.QuestionBank) bool {
+	if sessionData == nil {
+		return false
+	}
+	if sessionData.Permissions&models.MANAGE_QUIZZES_PERM != 0 {
+		return true
+	}
+	return bank.AuthorId != nil && *bank.AuthorId == sessionData.UserId
+}
+
+// Quizzes can draw from the banks the user can edit and from shared banks.
+func canUseBank(sessionData *middleware.SessionData, bank *models.QuestionBank) bool {
+	return bank.IsShared || canEditBank(sessionData, bank)
+}
+
+// Returns the banks the user can draw questions from.
+func availableBanks(ctx context.Context, sessionData *middleware.SessionData) ([]*models.QuestionBank, error) {
+	if sessionData == nil {
+		return []*models.QuestionBank{}, nil
+	}
+	if sessionData.Permissions&models.MANAGE_QUIZZES_PERM != 0 {
+		return repository.QuizRepositoryInstance.GetAllQuestionBanks(ctx)
+	}
+	return repository.QuizRepositoryInstance.GetUserQuestionBanks(ctx, sessionData.UserId)
+}
+
+func addDrawRules(ctx context.Context, sessionData *middleware.SessionData, quizId int32, version int32, rules []DrawRule) error {
+	for i, rule := range rules {
+		bank, err := repository.QuizRepositoryInstance.GetQuestionBank(ctx, rule.BankId)
+		if _, ok := err.(*apperrors.ErrNotFound); ok || (err == nil && !canUseBank(sessionData, bank)) {
+			return fmt.Errorf("invalid question bank")
+		} else if err != nil {
+			return err
+		}
+		err = repository.QuizRepositoryInstance.
+			AddDrawRule(ctx, &models.QuizDrawRule{
+				QuizId:        quizId,
+				Version:       version,
+				BankId:        rule.BankId,
+				Tag:           strings.TrimSpace(rule.Tag),
+				CategoryId:    rule.CategoryId,
+				QuestionCount: rule.Count,
+				Position:      int32(i + 1),
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getDrawRules(ctx context.Context, quizId int32, version int32) ([]DrawRule, error) {
+	ruleModels, err := repository.QuizRepositoryInstance.GetDrawRules(ctx, quizId, version)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]DrawRule, len(ruleModels))
+	for i, v := range ruleModels {
+		rules[i] = DrawRule{BankId: v.BankId, Tag: v.Tag, CategoryId: v.CategoryId, Count: v.QuestionCount}
+	}
+	return rules, nil
+}
+
+// Picks the questions of a new attempt: every question of the quiz version
+// followed by the questions drawn from the banks. A rule gets fewer
+// questions when the bank has not enough of them.
+func drawAttemptQuestions(ctx context.Context, quizModel *models.Quiz) ([]int32, error) {
+	questions, err := repository.QuizRepositoryInstance.
+		GetQuizVersionQuestions(ctx, quizModel.Id, quizModel.Version)
+	if err != nil {
+		return nil, err
+	}
+	picked := make(map[int32]bool)
+	questionIds := make([]int32, 0, len(questions))
+	for _, q := range questions {
+		picked[q.Id] = true
+		questionIds = append(questionIds, q.Id)
+	}
+
+	rules, err := repository.QuizRepositoryInstance.
+		GetDrawRules(ctx, quizModel.Id, quizModel.Version)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		bank, err := repository.QuizRepositoryInstance.GetQuestionBank(ctx, rule.BankId)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := repository.QuizRepositoryInstance.GetBankQuestions(ctx, bank.Id, bank.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := make([]int32, 0, len(pool))
+		for _, q := range pool {
+			if picked[q.Id] {
+				continue
+			}
+			if rule.Tag != "" && !strings.EqualFold(q.Tag, rule.Tag) {
+				continue
+			}
+			if rule.CategoryId != 0 && q.CategoryId != rule.CategoryId {
+				continue
+			}
+			candidates = append(candidates, q.Id)
+		}
+		rand.Shuffle(len(candidates), func(a, b int) {
+			candidates[a], candidates[b] = candidates[b], candidates[a]
+		})
+		if len(candidates) > int(rule.QuestionCount) {
+			candidates = candidates[:rule.QuestionCount]
+		}
+		for _, id := range candidates {
+			picked[id] = true
+			questionIds = append(questionIds, id)
+		}
+	}
+
+	return questionIds, nil
+}
+
+func BankIndexGetHandler(c *gin.Context) {
+	var sessionData *middleware.SessionData
+	data, ok := c.Get("sessionData")
+	if ok {
+		sessionData, _ = data.(*middleware.SessionData)
+	}
+
+	ctx := context.Background()
+	bankModels, err := availableBanks(ctx, sessionData)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bankIds := make([]int32, len(bankModels))
+	for i, v := range bankModels {
+		bankIds[i] = v.Id
+	}
+	allContents, err := repository.QuizRepositoryInstance.GetQuestionBankContents(ctx, bankIds)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	contents := make(map[int32]*models.QuestionBankContents, len(allContents))
+	for _, v := range allContents {
+		contents[v.BankId] = v
+	}
+
+	banks := make([]Bank, len(bankModels))
+	for i, v := range bankModels {
+		banks[i] = Bank{
+			Id:       v.Id,
+			Title:    v.Title,
+			IsShared: v.IsShared,
+			CanEdit:  canEditBank(sessionData, v),
+		}
+		if bankContents, ok := contents[v.Id]; ok {
+			banks[i].QuestionCount = bankContents.QuestionCount
+			banks[i].Tags = strings.Join(bankContents.Tags, ", ")
+		}
+		if v.Description != nil {
+			banks[i].Description = *v.Description
+		}
+	}
+
+	baseHInterface, _ := c.Get("BaseH")
+	baseH, _ := baseHInterface.(*gin.H)
+	c.HTML(http.StatusOK, "bank_list.html", utility.MergeMaps(*baseH, gin.H{
+		"title": "Question Banks",
+		"banks": banks}))
+}
+
+func BankCreateFormGetHandler(c *gin.Context) {
+	ctx := context.Background()
+	categories, err := repository.QuizRepositoryInstance.GetAllCategories(ctx)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	baseHInterface, _ := c.Get("BaseH")
+	baseH, _ := baseHInterface.(*gin.H)
+	c.HTML(http.StatusOK, "bank_form.html", utility.MergeMaps(*baseH, gin.H{
+		"title":      "Question Banks",
+		"categories": categories,
+		"bank":       nil,
+		"action":     "/banks/create"}))
+}
+
+func BankCreatePostHandler(c *gin.Context) {
+	var (
+		authorId int32
+		bank     Bank
+	)
+	data, ok := c.Get("sessionData")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+	if sessionData, ok := data.(*middleware.SessionData); ok {
+		authorId = sessionData.UserId
+	}
+	if err := c.ShouldBindJSON(&bank); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	err := repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
+
+		bankId, err := repository.QuizRepositoryInstance.
+			AddQuestionBank(ctx, &models.QuestionBank{
+				AuthorId:    &authorId,
+				Title:       bank.Title,
+				Description: &bank.Description,
+				IsShared:    bank.IsShared,
+			})
+		if err != nil {
+			return err
+		}
+
+		return addQuestions(ctx, &models.Question{BankId: bankId, Version: 1}, bank.Questions)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/banks")
+}
+
+func BankEditFormGetHandler(c *gin.Context) {
+	var sessionData *middleware.SessionData
+	data, ok := c.Get("sessionData")
+	if ok {
+		sessionData, _ = data.(*middleware.SessionData)
+	}
+
+	i, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	id := int32(i)
+
+	ctx := context.Background()
+	bankModel, err := repository.QuizRepositoryInstance.GetQuestionBank(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !canEditBank(sessionData, bankModel) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not enough permissions"})
+		return
+	}
+
+	bank := Bank{
+		Id:       bankModel.Id,
+		Title:    bankModel.Title,
+		IsShared: bankModel.IsShared,
+	}
+	if bankModel.Description != nil {
+		bank.Description = *bankModel.Description
+	}
+	questionModels, err := repository.QuizRepositoryInstance.
+		GetBankQuestions(ctx, id, bankModel.Version)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	bank.Questions, err = loadQuestionsWithAnswers(ctx, questionModels)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	categories, err := repository.QuizRepositoryInstance.GetAllCategories(ctx)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	baseHInterface, _ := c.Get("BaseH")
+	baseH, _ := baseHInterface.(*gin.H)
+	c.HTML(http.StatusOK, "bank_form.html", utility.MergeMaps(*baseH, gin.H{
+		"title":      "Question Banks",
+		"categories": categories,
+		"bank":       bank,
+		"action":     fmt.Sprintf("/banks/%d/edit", id)}))
+}
+
+// Saving moves the bank to a new version, attempts keep the questions
+// they were given.
+func BankEditPostHandler(c *gin.Context) {
+	var (
+		sessionData *middleware.SessionData
+		bank        Bank
+	)
+	data, ok := c.Get("sessionData")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+	sessionData, _ = data.(*middleware.SessionData)
+
+	i, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	id := int32(i)
+
+	if err := c.ShouldBindJSON(&bank); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	bankModel, err := repository.QuizRepositoryInstance.GetQuestionBank(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !canEditBank(sessionData, bankModel) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not enough permissions"})
+		return
+	}
+
+	err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
+
+		version, err := repository.QuizRepositoryInstance.
+			EditQuestionBank(ctx, id, bank.Title, bank.Description, bank.IsShared)
+		if err != nil {
+			return err
+		}
+
+		return addQuestions(ctx, &models.Question{BankId: id, Version: version}, bank.Questions)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/banks")
+}
@@ -0,0 +1,130 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/middleware"
+	"quiz_platform/internal/misc/apperrors"
+	"quiz_platform/internal/models"
+	"quiz_platform/internal/utility"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Random bytes in a verification code.
+const CERTIFICATE_CODE_BYTES = 8
+
+// Certificate shown to its owner or to anyone verifying it.
+type Certificate struct {
+	Code      string
+	QuizId    int32
+	Username  string
+	QuizTitle string
+	Score     string
+	IssuedAt  time.Time
+}
+
+func toCertificate(v *models.Certificate) Certificate {
+	return Certificate{
+		Code:      v.Code,
+		QuizId:    v.QuizId,
+		Username:  v.Username,
+		QuizTitle: v.QuizTitle,
+		Score:     fmt.Sprintf("%.2f%%", v.Score*100),
+		IssuedAt:  v.IssuedAt,
+	}
+}
+
+// Issues a certificate when the finished attempt passed the quiz. Attempts
+// with ungraded answers get it once the grading is done, only the first
+// pass of a quiz is certified.
+func issueCertificate(ctx context.Context, attemptId int32, issuedAt time.Time) error {
+	partTime, err := repository.QuizRepositoryInstance.GetParticipationTime(ctx, attemptId)
+	if err != nil {
+		return err
+	}
+	if partTime.Passed == nil || !*partTime.Passed || partTime.Score == nil {
+		return nil
+	}
+	pending, err := repository.QuizRepositoryInstance.CountPendingReviews(ctx, []int32{partTime.Id})
+	if err != nil || pending > 0 {
+		return err
+	}
+
+	user, err := repository.UserRepositoryInstance.GetUserById(ctx, partTime.UserId)
+	if err != nil {
+		return err
+	}
+	quizVersion, err := repository.QuizRepositoryInstance.
+		GetQuizVersion(ctx, partTime.QuizId, partTime.QuizVersion)
+	if err != nil {
+		return err
+	}
+	code, err := utility.RandomCode(CERTIFICATE_CODE_BYTES)
+	if err != nil {
+		return err
+	}
+	return repository.QuizRepositoryInstance.AddCertificate(ctx, &models.Certificate{
+		Code:      code,
+		UserId:    partTime.UserId,
+		QuizId:    partTime.QuizId,
+		AttemptId: partTime.Id,
+		Username:  user.UserName,
+		QuizTitle: quizVersion.Title,
+		Score:     *partTime.Score,
+		IssuedAt:  issuedAt,
+	})
+}
+
+func CertificateIndexGetHandler(c *gin.Context) {
+	data, ok := c.Get("sessionData")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+	sessionData, _ := data.(*middleware.SessionData)
+
+	ctx := context.Background()
+	certificateModels, err := repository.QuizRepositoryInstance.
+		GetUserCertificates(ctx, sessionData.UserId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	certificates := make([]Certificate, len(certificateModels))
+	for i, v := range certificateModels {
+		certificates[i] = toCertificate(v)
+	}
+
+	baseHInterface, _ := c.Get("BaseH")
+	baseH, _ := baseHInterface.(*gin.H)
+	c.HTML(http.StatusOK, "certificate_list.html", utility.MergeMaps(*baseH, gin.H{
+		"title":        "My Certificates",
+		"certificates": certificates}))
+}
+
+// Shows the certificate with the verification code to anyone, which
+// confirms that it was issued here.
+func CertificateGetHandler(c *gin.Context) {
+	ctx := context.Background()
+	certificateModel, err := repository.QuizRepositoryInstance.
+		GetCertificate(ctx, c.Param("code"))
+	if _, ok := err.(*apperrors.ErrNotFound); ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "certificate not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	baseHInterface, _ := c.Get("BaseH")
+	baseH, _ := baseHInterface.(*gin.H)
+	c.HTML(http.StatusOK, "certificate.html", utility.MergeMaps(*baseH, gin.H{
+		"title":       "Certificate",
+		"certificate": toCertificate(certificateModel)}))
+}
@@ -0,0 +1,42 @@
+package quiz
+
+import (
+	"context"
+	"strings"
+
+	"quiz_platform/internal/handler/repository"
+)
+
+func addQuestionExplanation(ctx context.Context, q *Question) error {
+	explanation := strings.TrimSpace(q.Explanation)
+	if explanation == "" {
+		return nil
+	}
+	return repository.QuizRepositoryInstance.AddQuestionExplanation(ctx, q.Id, explanation)
+}
+
+// Returns the explanations of the questions by their ids.
+func questionExplanations(ctx context.Context, questionIds []int32) (map[int32]string, error) {
+	explanationModels, err := repository.QuizRepositoryInstance.GetQuestionExplanations(ctx, questionIds)
+	if err != nil {
+		return nil, err
+	}
+	explanations := make(map[int32]string, len(explanationModels))
+	for _, v := range explanationModels {
+		explanations[v.QuestionId] = v.Explanation
+	}
+	return explanations, nil
+}
+
+// Returns the feedback of the choices of the question by choice ids.
+func choiceFeedback(ctx context.Context, questionId int32) (map[int32]string, error) {
+	feedbackModels, err := repository.QuizRepositoryInstance.GetChoiceFeedback(ctx, questionId)
+	if err != nil {
+		return nil, err
+	}
+	feedback := make(map[int32]string, len(feedbackModels))
+	for _, v := range feedbackModels {
+		feedback[v.ChoiceId] = v.Feedback
+	}
+	return feedback, nil
+}
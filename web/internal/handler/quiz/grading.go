@@ -0,0 +1,490 @@
+package quiz
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"quiz_platform/internal/models"
+	"quiz_platform/internal/utility"
+)
+
+// Scoring modes of the questions with several correct choices.
+const (
+	SCORING_ALL_OR_NOTHING = "all_or_nothing"
+	SCORING_PARTIAL        = "partial"
+)
+
+// Scoring modes of the ordering questions.
+const (
+	SCORING_EXACT    = "exact"
+	SCORING_POSITION = "position"
+	SCORING_KENDALL  = "kendall"
+)
+
+// Scoring mode of the text questions graded by a reviewer.
+const SCORING_MANUAL = "manual"
+
+// Tolerance modes of the numeric questions.
+const (
+	TOLERANCE_ABSOLUTE = "absolute"
+	TOLERANCE_RELATIVE = "relative"
+)
+
+// Match modes of the accepted text answers.
+const (
+	MATCH_EXACT       = "exact"
+	MATCH_NORMALIZED  = "normalized"
+	MATCH_LEVENSHTEIN = "levenshtein"
+	MATCH_REGEX       = "regex"
+	// Compares the answer as a number, used for the formula results
+	// of parameterized text questions.
+	MATCH_NUMERIC = "numeric"
+)
+
+// Number with optional thousands separated by spaces, decimal comma and
+// exponent, followed by an optional unit.
+var numericAnswerRegexp = regexp.MustCompile(
+	`^([-+]?(?:\d{1,3}(?: \d{3})+|\d+)?(?:[.,]\d+)?(?:[eE][-+]?\d+)?)\s*(.*)$`)
+
+// Number with thousands separated by commas, like 1,000. The comma is read
+// as the decimal separator, so such numbers are rejected instead of being
+// silently taken for 1.0.
+var commaThousandsRegexp = regexp.MustCompile(`^[-+]?[1-9]\d{0,2}(?:,\d{3})+$`)
+
+// Placeholder of a blank in the text of a cloze question, like {{1}}.
+var clozeBlankRegexp = regexp.MustCompile(`\{\{\s*(\d+)\s*\}\}`)
+
+// Answer to a single question. Accepts either a single value
+// or a list of values in JSON.
+type Answer []string
+
+func (a *Answer) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Answer{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = Answer(list)
+	return nil
+}
+
+// Returns the first value of the answer or an empty string.
+func (a Answer) Value() string {
+	if len(a) == 0 {
+		return ""
+	}
+	return a[0]
+}
+
+// Parses the answer values as identifiers.
+func (a Answer) Ids() ([]int32, error) {
+	ids := make([]int32, len(a))
+	for i, v := range a {
+		id, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = int32(id)
+	}
+	return ids, nil
+}
+
+// Returns the points earned for a question with the given credit in range [0, 1].
+// Answered questions that earned no credit at all are penalized, question
+// types without negative marking reset the penalty on validation.
+func questionPoints(q *models.Question, credit float32, answered bool) float32 {
+	if credit == 0 && answered && q.NegativePoints > 0 {
+		return -float32(q.NegativePoints)
+	}
+	return credit * float32(q.Points)
+}
+
+// Parses the answer values of the form "pairId:matchedPairId" into
+// a map from the left item to the chosen right item.
+func (a Answer) Matches() (map[int32]int32, error) {
+	matches := make(map[int32]int32, len(a))
+	for _, v := range a {
+		left, right, ok := strings.Cut(v, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid match")
+		}
+		pairId, err := strconv.ParseInt(left, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		matchedPairId, err := strconv.ParseInt(right, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		matches[int32(pairId)] = int32(matchedPairId)
+	}
+	return matches, nil
+}
+
+// Returns the credit in range [0, 1] for the selected choices
+// of a question with several correct choices.
+func gradeMultiChoice(choices []*models.Choice, selectedIds []int32, scoringMode string) float32 {
+	selected := make(map[int32]bool, len(selectedIds))
+	for _, id := range selectedIds {
+		selected[id] = true
+	}
+
+	correctCount, hits, misses := 0, 0, 0
+	for _, c := range choices {
+		if c.IsCorrect {
+			correctCount++
+		}
+		if selected[c.Id] {
+			if c.IsCorrect {
+				hits++
+			} else {
+				misses++
+			}
+		}
+	}
+	if correctCount == 0 {
+		return 0
+	}
+
+	if scoringMode == SCORING_PARTIAL {
+		// Every wrong selection cancels out one right selection.
+		credit := float32(hits-misses) / float32(correctCount)
+		if credit < 0 {
+			return 0
+		}
+		return credit
+	}
+
+	if hits == correctCount && misses == 0 {
+		return 1
+	}
+	return 0
+}
+
+// Splits an answer like "299 792 458 m/s" into its value and unit.
+func parseNumericAnswer(answer string) (float64, string, error) {
+	match := numericAnswerRegexp.FindStringSubmatch(strings.TrimSpace(answer))
+	if match == nil || strings.TrimSpace(match[1]) == "" {
+		return 0, "", fmt.Errorf("invalid number")
+	}
+
+	if commaThousandsRegexp.MatchString(strings.TrimSpace(match[1])) {
+		return 0, "", fmt.Errorf("invalid number")
+	}
+	number := strings.ReplaceAll(strings.TrimSpace(match[1]), " ", "")
+	number = strings.Replace(number, ",", ".", 1)
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, "", err
+	}
+
+	unit := strings.TrimSpace(match[2])
+	if unit != "" && (unicode.IsDigit(rune(unit[0])) || unit[0] == '.' || unit[0] == ',') {
+		return 0, "", fmt.Errorf("invalid number")
+	}
+	return value, unit, nil
+}
+
+func normalizeUnit(unit string) string {
+	return strings.ToLower(strings.Join(strings.Fields(unit), ""))
+}
+
+// Checks whether the answer is within the tolerance of the target value.
+// The unit may be omitted, otherwise it has to be one of the accepted units.
+func gradeNumeric(key *models.NumericQuestionAnswer, answer string) bool {
+	value, unit, err := parseNumericAnswer(answer)
+	if err != nil {
+		return false
+	}
+
+	if unit != "" {
+		accepted := false
+		for _, u := range key.Units {
+			if normalizeUnit(u) == normalizeUnit(unit) {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			return false
+		}
+	}
+
+	tolerance := key.Tolerance
+	if key.ToleranceMode == TOLERANCE_RELATIVE {
+		tolerance *= math.Abs(key.TargetValue)
+	}
+	// Guards against floating point rounding of the exact answers.
+	epsilon := 1e-9 * math.Max(1, math.Abs(key.TargetValue))
+
+	return math.Abs(value-key.TargetValue) <= tolerance+epsilon
+}
+
+// Formats the right answer of a numeric question for displaying.
+func describeNumeric(key *models.NumericQuestionAnswer) string {
+	res := strconv.FormatFloat(key.TargetValue, 'f', -1, 64)
+	if len(key.Units) > 0 {
+		res += " " + key.Units[0]
+	}
+	if key.Tolerance > 0 {
+		if key.ToleranceMode == TOLERANCE_RELATIVE {
+			res += fmt.Sprintf(" (±%s%%)", strconv.FormatFloat(key.Tolerance*100, 'f', -1, 64))
+		} else {
+			res += fmt.Sprintf(" (±%s)", strconv.FormatFloat(key.Tolerance, 'f', -1, 64))
+		}
+	}
+	return res
+}
+
+// Compiles the pattern of an accepted answer, the pattern
+// has to match the whole user answer.
+func compileAnswerRegexp(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+// Checks whether the user answer matches a single accepted answer.
+func matchTextAnswer(key *models.TextQuestionAnswer, answer string) bool {
+	switch key.MatchMode {
+	case MATCH_NORMALIZED:
+		return utility.NormalizeText(key.RightAnswer) == utility.NormalizeText(answer)
+	case MATCH_LEVENSHTEIN:
+		distance := utility.Levenshtein(
+			utility.NormalizeText(key.RightAnswer), utility.NormalizeText(answer))
+		return distance <= int(key.MaxDistance)
+	case MATCH_REGEX:
+		re, err := compileAnswerRegexp(key.RightAnswer)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(answer)
+	case MATCH_NUMERIC:
+		target, err := strconv.ParseFloat(key.RightAnswer, 64)
+		if err != nil {
+			return false
+		}
+		return gradeNumeric(&models.NumericQuestionAnswer{TargetValue: target}, answer)
+	default:
+		return key.RightAnswer == answer
+	}
+}
+
+// Grades a text answer against all accepted answers of the question.
+// Used both for scoring and for the result page so that they always agree.
+func gradeText(keys []*models.TextQuestionAnswer, answer string) bool {
+	for _, key := range keys {
+		if matchTextAnswer(key, answer) {
+			return true
+		}
+	}
+	return false
+}
+
+// Formats the accepted answers of a text question for displaying.
+func describeText(keys []*models.TextQuestionAnswer) string {
+	answers := make([]string, 0, len(keys))
+	patterns := make([]string, 0)
+	for _, key := range keys {
+		if key.MatchMode == MATCH_REGEX {
+			patterns = append(patterns, key.RightAnswer)
+		} else {
+			answers = append(answers, key.RightAnswer)
+		}
+	}
+	if len(answers) == 0 {
+		return "matching " + strings.Join(patterns, " or ")
+	}
+	return strings.Join(answers, " / ")
+}
+
+// Returns the credit in range [0, 1] for the sequence of item ids
+// given by the user. Items are expected in the right order.
+func gradeOrdering(items []*models.OrderingItem, itemIds []int32, scoringMode string) float32 {
+	n := len(items)
+	if n == 0 || len(itemIds) != n {
+		return 0
+	}
+
+	rank := make(map[int32]int, n)
+	for i, item := range items {
+		rank[item.Id] = i
+	}
+	seen := make(map[int32]bool, n)
+	for _, id := range itemIds {
+		if _, ok := rank[id]; !ok || seen[id] {
+			return 0
+		}
+		seen[id] = true
+	}
+
+	placed := 0
+	for i, id := range itemIds {
+		if rank[id] == i {
+			placed++
+		}
+	}
+
+	switch scoringMode {
+	case SCORING_POSITION:
+		return float32(placed) / float32(n)
+	case SCORING_KENDALL:
+		if n == 1 {
+			return 1
+		}
+		// One minus the normalized Kendall tau distance.
+		discordant := 0
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				if rank[itemIds[i]] > rank[itemIds[j]] {
+					discordant++
+				}
+			}
+		}
+		pairs := n * (n - 1) / 2
+		return 1 - float32(discordant)/float32(pairs)
+	default:
+		if placed == n {
+			return 1
+		}
+		return 0
+	}
+}
+
+// Checks whether the left item of the pair was matched with its right item.
+// Right items are compared by text, so that duplicates are interchangeable.
+func isPairMatched(pairs []*models.MatchingPair, pair *models.MatchingPair, matchedPairId int32) bool {
+	for _, p := range pairs {
+		if p.Id == matchedPairId {
+			return p.RightText == pair.RightText
+		}
+	}
+	return false
+}
+
+// Returns the credit in range [0, 1] for the matched pairs,
+// every correctly matched left item gives an equal share.
+func gradeMatching(pairs []*models.MatchingPair, matches map[int32]int32) float32 {
+	if len(pairs) == 0 {
+		return 0
+	}
+
+	matched := 0
+	for _, pair := range pairs {
+		if isPairMatched(pairs, pair, matches[pair.Id]) {
+			matched++
+		}
+	}
+	return float32(matched) / float32(len(pairs))
+}
+
+// Part of the text of a cloze question, either plain text or a blank.
+type ClozeSegment struct {
+	Text  string
+	Blank int32
+}
+
+// Splits the text of a cloze question into plain text and blanks.
+func splitCloze(text string) []ClozeSegment {
+	segments := make([]ClozeSegment, 0)
+	last := 0
+	for _, match := range clozeBlankRegexp.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] > last {
+			segments = append(segments, ClozeSegment{Text: text[last:match[0]]})
+		}
+		number, err := strconv.ParseInt(text[match[2]:match[3]], 10, 32)
+		if err != nil || number <= 0 {
+			segments = append(segments, ClozeSegment{Text: text[match[0]:match[1]]})
+		} else {
+			segments = append(segments, ClozeSegment{Blank: int32(number)})
+		}
+		last = match[1]
+	}
+	if last < len(text) {
+		segments = append(segments, ClozeSegment{Text: text[last:]})
+	}
+	return segments
+}
+
+// Returns the numbers of the blanks in the order they appear in the text.
+func clozeBlanks(text string) []int32 {
+	blanks := make([]int32, 0)
+	for _, segment := range splitCloze(text) {
+		if segment.Blank != 0 {
+			blanks = append(blanks, segment.Blank)
+		}
+	}
+	return blanks
+}
+
+// Checks the answer to a single blank. Blanks with inline options are
+// graded by the chosen option, the rest by their accepted answers.
+func gradeBlank(keys []*models.TextQuestionAnswer, options []*models.ClozeBlankOption, answer string) bool {
+	if len(options) > 0 {
+		for _, o := range options {
+			if o.IsCorrect && o.OptionText == answer {
+				return true
+			}
+		}
+		return false
+	}
+	return gradeText(keys, answer)
+}
+
+// Formats the right answer to a single blank for displaying.
+func describeBlank(keys []*models.TextQuestionAnswer, options []*models.ClozeBlankOption) string {
+	if len(options) > 0 {
+		answers := make([]string, 0)
+		for _, o := range options {
+			if o.IsCorrect {
+				answers = append(answers, o.OptionText)
+			}
+		}
+		return strings.Join(answers, " / ")
+	}
+	return describeText(keys)
+}
+
+func groupKeysByBlank(keys []*models.TextQuestionAnswer) map[int32][]*models.TextQuestionAnswer {
+	res := make(map[int32][]*models.TextQuestionAnswer)
+	for _, key := range keys {
+		res[key.BlankNumber] = append(res[key.BlankNumber], key)
+	}
+	return res
+}
+
+func groupOptionsByBlank(options []*models.ClozeBlankOption) map[int32][]*models.ClozeBlankOption {
+	res := make(map[int32][]*models.ClozeBlankOption)
+	for _, option := range options {
+		res[option.BlankNumber] = append(res[option.BlankNumber], option)
+	}
+	return res
+}
+
+// Returns the credit in range [0, 1] for the answers to the blanks,
+// every correctly filled blank gives an equal share.
+func gradeCloze(blanks []int32, keys []*models.TextQuestionAnswer,
+	options []*models.ClozeBlankOption, answers map[int32]string) float32 {
+	if len(blanks) == 0 {
+		return 0
+	}
+
+	blankKeys := groupKeysByBlank(keys)
+	blankOptions := groupOptionsByBlank(options)
+	filled := 0
+	for _, blank := range blanks {
+		answer, ok := answers[blank]
+		if ok && gradeBlank(blankKeys[blank], blankOptions[blank], answer) {
+			filled++
+		}
+	}
+	return float32(filled) / float32(len(blanks))
+}
@@ -0,0 +1,90 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"quiz_platform/internal/models"
+)
+
+// Everything that differs between the kinds of questions. Types are
+// registered under the name stored in questions.question_type.
+type QuestionType interface {
+	Name() string
+
+	// Checks the question sent by the author and normalizes its settings.
+	Validate(q *Question) error
+
+	// Stores the right answers of an already inserted question.
+	Save(ctx context.Context, q *Question) error
+
+	// Loads the right answers of the question for editing.
+	Load(ctx context.Context, q *models.Question, dst *Question) error
+
+	// Loads the data needed to show the question to a participant
+	// in the attempt.
+	Render(ctx context.Context, attemptId int32, q *models.Question, dst *Question) error
+
+	// Stores the answer given in the attempt and returns its credit
+	// in range [0, 1].
+	Grade(ctx context.Context, attemptId int32, q *models.Question, answer Answer) (float32, error)
+
+	// Fills the result of the answer stored in the attempt. Returns the
+	// credit of the answer and whether the question was answered at all.
+	DescribeResult(ctx context.Context, attemptId int32, q *models.Question, dst *AnsweredQuestion) (float32, bool, error)
+
+	// Loads the answer stored in the attempt in the form the participant
+	// sends it. Returns nil when the question was left unanswered.
+	LoadAnswer(ctx context.Context, attemptId int32, q *models.Question) (Answer, error)
+}
+
+// Implemented by the question types whose answer key can be corrected in
+// place, keeping the answers stored in the attempts.
+type keyCorrector interface {
+	// Checks the corrected key the same way as Validate and replaces
+	// the answer key of the question with it.
+	CorrectKey(ctx context.Context, q *models.Question, key *QuestionKeyRequest) error
+}
+
+var questionTypes = registerQuestionTypes(
+	&choiceType{},
+	&multiType{},
+	&textType{},
+	&numericType{},
+	&orderingType{},
+	&matchingType{},
+	&clozeType{},
+)
+
+func registerQuestionTypes(types ...QuestionType) map[string]QuestionType {
+	res := make(map[string]QuestionType, len(types))
+	for _, t := range types {
+		res[t.Name()] = t
+	}
+	return res
+}
+
+// Adds a question type, replacing the registered type with the same name.
+// Has to be called before the question types are synced with the database.
+func RegisterQuestionType(t QuestionType) {
+	questionTypes[t.Name()] = t
+}
+
+// Returns the names of all registered question types in sorted order.
+func QuestionTypeNames() []string {
+	names := make([]string, 0, len(questionTypes))
+	for name := range questionTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func getQuestionType(name string) (QuestionType, error) {
+	t, ok := questionTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown question type %q", name)
+	}
+	return t, nil
+}
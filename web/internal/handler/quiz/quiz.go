@@ -3,12 +3,15 @@ package quiz
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"quiz_platform/internal/handler/repository"
 	"quiz_platform/internal/middleware"
 	"quiz_platform/internal/misc/apperrors"
+	"quiz_platform/internal/models"
 	"quiz_platform/internal/utility"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -19,14 +22,65 @@ type Choice struct {
 	QuestionId int32  `json:"-"`
 	Text       string `json:"text" binding:"required"`
 	IsCorrect  bool   `json:"is_correct"`
+	Feedback   string `json:"feedback,omitempty"`
 }
 
 type Question struct {
-	Id          int32    `json:"-"`
-	Text        string   `json:"text" binding:"required"`
-	Type        string   `json:"type" binding:"required,oneof=choice text"`
-	Choices     []Choice `json:"choices,omitempty"`
-	RightAnswer string   `json:"right_answer,omitempty"`
+	Id             int32          `json:"-"`
+	Text           string         `json:"text" binding:"required"`
+	Type           string         `json:"type" binding:"required"`
+	Tag            string         `json:"tag,omitempty"`
+	CategoryId     int32          `json:"category_id,omitempty"`
+	ScoringMode    string         `json:"scoring_mode,omitempty" binding:"omitempty,oneof=all_or_nothing partial exact position kendall manual"`
+	Points         float64        `json:"points,omitempty" binding:"omitempty,gt=0"`
+	NegativePoints float64        `json:"negative_points,omitempty" binding:"min=0"`
+	Choices        []Choice       `json:"choices,omitempty"`
+	Items          []Choice       `json:"items,omitempty"`
+	Pairs          []MatchingPair `json:"pairs,omitempty" binding:"dive"`
+	Blanks         []ClozeBlank   `json:"blanks,omitempty" binding:"dive"`
+	Options        []Choice       `json:"-"`
+	Fields         []BlankField   `json:"-"`
+	RightAnswer    string         `json:"right_answer,omitempty"`
+	Numeric        *NumericAnswer `json:"numeric,omitempty"`
+
+	RightAnswers []TextAnswerRule `json:"right_answers,omitempty" binding:"dive"`
+
+	Formula   string             `json:"formula,omitempty"`
+	Variables []QuestionVariable `json:"variables,omitempty" binding:"dive"`
+
+	Explanation string `json:"explanation,omitempty"`
+}
+
+type MatchingPair struct {
+	Id    int32  `json:"-"`
+	Left  string `json:"left" binding:"required"`
+	Right string `json:"right" binding:"required"`
+}
+
+type ClozeBlank struct {
+	Number       int32            `json:"number" binding:"min=1"`
+	RightAnswers []TextAnswerRule `json:"right_answers,omitempty" binding:"dive"`
+	Options      []Choice         `json:"options,omitempty" binding:"dive"`
+}
+
+// Part of a cloze question shown to the participant.
+type BlankField struct {
+	Text    string
+	Blank   int32
+	Options []string
+}
+
+type TextAnswerRule struct {
+	Text        string `json:"text" binding:"required"`
+	MatchMode   string `json:"match_mode" binding:"omitempty,oneof=exact normalized levenshtein regex"`
+	MaxDistance int32  `json:"max_distance" binding:"min=0"`
+}
+
+type NumericAnswer struct {
+	Value         float64  `json:"value"`
+	Tolerance     float64  `json:"tolerance" binding:"min=0"`
+	ToleranceMode string   `json:"tolerance_mode" binding:"omitempty,oneof=absolute relative"`
+	Units         []string `json:"units,omitempty"`
 }
 
 type Quiz struct {
@@ -35,31 +89,114 @@ type Quiz struct {
 	Description string     `json:"description" binding:"required"`
 	Categories  []string   `json:"categories" binding:"required"`
 	Questions   []Question `json:"questions" binding:"required,dive"`
+	TimeLimit   int32      `json:"time_limit_seconds" binding:"min=0"`
+	MaxAttempts int32      `json:"max_attempts" binding:"min=0"`
+	Cooldown    int32      `json:"cooldown_seconds" binding:"min=0"`
+	ScorePolicy string     `json:"score_policy" binding:"omitempty,oneof=best last average first"`
+
+	ShuffleQuestions bool       `json:"shuffle_questions"`
+	ShuffleChoices   bool       `json:"shuffle_choices"`
+	DrawRules        []DrawRule `json:"draw_rules" binding:"dive"`
+
+	Status   string     `json:"status" binding:"omitempty,oneof=draft published closed archived"`
+	OpensAt  *time.Time `json:"opens_at"`
+	ClosesAt *time.Time `json:"closes_at"`
+
+	RevealPolicy string  `json:"reveal_policy" binding:"omitempty,oneof=immediately after_close after_last_attempt correctness never"`
+	PassMark     float64 `json:"pass_mark" binding:"min=0,max=100"`
+
+	AttemptId        int32             `json:"-"`
+	RemainingSeconds int32             `json:"-"`
+	AttemptsLeft     string            `json:"-"`
+	SavedAnswers     map[string]Answer `json:"-"`
 
 	TotalAttempts int32  `json:"-"`
 	AverageScore  string `json:"-"`
 	AverageTime   string `json:"-"`
+	UserScore     string `json:"-"`
+	CanEdit       bool   `json:"-"`
+	IsOpen        bool   `json:"-"`
+	IsPassed      bool   `json:"-"`
+	PassRate      string `json:"-"`
 }
 
 type Submission struct {
-	QuizId  int32             `json:"quiz_id"`
-	Answers map[string]string `json:"answers"`
+	QuizId    int32             `json:"quiz_id"`
+	AttemptId int32             `json:"attempt_id" binding:"required"`
+	Answers   map[string]Answer `json:"answers"`
 }
 
 type QuizResult struct {
+	QuizId      int32
+	Number      int
 	Title       string
 	Description string
 	Score       string
+	Points      string
 	Time        string
 	Questions   []AnsweredQuestion `json:"questions" binding:"required,dive"`
+
+	IsProvisional   bool
+	Outcome         string
+	PassMark        string
+	ShowCorrectness bool
+	ShowAnswers     bool
+}
+
+// Attempt of the user shown in the attempt history.
+type Attempt struct {
+	Id         int32
+	Number     int
+	StartedAt  time.Time
+	IsFinished bool
+	Time       string
+	Score      string
+	Points     string
 }
 
 type AnsweredQuestion struct {
 	Text        string `json:"text" binding:"required"`
-	Type        string `json:"type" binding:"required,oneof=choice text"`
+	Type        string `json:"type" binding:"required"`
 	RightAnswer string `json:"right_answer,omitempty"`
 	UserAnswer  string
 	IsCorrect   bool
+	Selections  []SelectedChoice
+	Placements  []PlacedItem
+	Matches     []MatchedPair
+	Segments    []FilledBlank
+	Points      string
+	Feedback    string
+	IsPending   bool
+
+	Explanation    string
+	AnswerFeedback string
+}
+
+type FilledBlank struct {
+	Text        string
+	Blank       int32
+	Answer      string
+	RightAnswer string
+	IsCorrect   bool
+}
+
+type MatchedPair struct {
+	Left        string
+	Right       string
+	RightAnswer string
+	IsCorrect   bool
+}
+
+type PlacedItem struct {
+	Text      string
+	IsCorrect bool
+}
+
+type SelectedChoice struct {
+	Text       string
+	IsSelected bool
+	IsCorrect  bool
+	Feedback   string
 }
 
 func formatDuration(d time.Duration) string {
@@ -73,6 +210,200 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%02d:%02d:%02d:%04d", hours, minutes, seconds, milliseconds)
 }
 
+// Formats earned points out of the maximum, like "7.5 / 10".
+func formatPoints(points float64, maxPoints float64) string {
+	round := func(v float64) string {
+		return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	}
+	return round(points) + " / " + round(maxPoints)
+}
+
+// Quizzes can be edited by their authors and by quiz managers.
+func canEditQuiz(sessionData *middleware.SessionData, quizModel *models.Quiz) bool {
+	if sessionData == nil {
+		return false
+	}
+	if sessionData.Permissions&models.MANAGE_QUIZZES_PERM != 0 {
+		return true
+	}
+	return quizModel.AuthorId != nil && *quizModel.AuthorId == sessionData.UserId
+}
+
+// Settings of the quiz which are kept outside of its versions.
+func quizSettings(quizId int32, quiz *Quiz) *models.Quiz {
+	settings := &models.Quiz{
+		Id:               quizId,
+		ScorePolicy:      quiz.ScorePolicy,
+		ShuffleQuestions: quiz.ShuffleQuestions,
+		ShuffleChoices:   quiz.ShuffleChoices,
+		Status:           quiz.Status,
+		OpensAt:          quiz.OpensAt,
+		ClosesAt:         quiz.ClosesAt,
+		RevealPolicy:     quiz.RevealPolicy,
+	}
+	if settings.ScorePolicy == "" {
+		settings.ScorePolicy = SCORE_POLICY_LAST
+	}
+	if settings.Status == "" {
+		settings.Status = QUIZ_STATUS_PUBLISHED
+	}
+	if settings.RevealPolicy == "" {
+		settings.RevealPolicy = REVEAL_IMMEDIATELY
+	}
+	if quiz.TimeLimit > 0 {
+		settings.TimeLimitSeconds = &quiz.TimeLimit
+	}
+	if quiz.MaxAttempts > 0 {
+		settings.MaxAttempts = &quiz.MaxAttempts
+	}
+	if quiz.Cooldown > 0 {
+		settings.CooldownSeconds = &quiz.Cooldown
+	}
+	if quiz.PassMark > 0 {
+		passMark := quiz.PassMark / 100
+		settings.PassMark = &passMark
+	}
+	return settings
+}
+
+func validateQuizWindow(quiz *Quiz) error {
+	if quiz.OpensAt != nil && quiz.ClosesAt != nil && !quiz.OpensAt.Before(*quiz.ClosesAt) {
+		return fmt.Errorf("invalid quiz window")
+	}
+	return nil
+}
+
+func parseCategoryIds(categories []string) ([]int32, error) {
+	categoryIds := make([]int32, len(categories))
+	for i, v := range categories {
+		categoryId, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		categoryIds[i] = int32(categoryId)
+	}
+	if len(categoryIds) == 0 {
+		return nil, fmt.Errorf("invalid category")
+	}
+	return categoryIds, nil
+}
+
+// Inserts questions and draw rules as a part of the given quiz version.
+func addQuizQuestions(ctx context.Context, sessionData *middleware.SessionData, quizId int32, version int32, quiz *Quiz) error {
+	if len(quiz.Questions) == 0 && len(quiz.DrawRules) == 0 {
+		return fmt.Errorf("invalid question count")
+	}
+	err := addQuestions(ctx, &models.Question{QuizId: quizId, Version: version}, quiz.Questions)
+	if err != nil {
+		return err
+	}
+	return addDrawRules(ctx, sessionData, quizId, version, quiz.DrawRules)
+}
+
+// Inserts questions with their answers, the owner holds the quiz or
+// the bank they belong to together with its version.
+func addQuestions(ctx context.Context, owner *models.Question, questions []Question) error {
+	types := make([]QuestionType, len(questions))
+	for i := range questions {
+		questionType, err := getQuestionType(questions[i].Type)
+		if err != nil {
+			return err
+		}
+		if questions[i].Points == 0 {
+			questions[i].Points = 1
+		}
+		if err = questionType.Validate(&questions[i]); err != nil {
+			return err
+		}
+		if err = validateVariables(&questions[i]); err != nil {
+			return err
+		}
+		types[i] = questionType
+	}
+
+	var err error
+	for i, v := range questions {
+		questions[i].Id, err = repository.QuizRepositoryInstance.
+			AddQuestion(ctx, &models.Question{
+				QuizId:         owner.QuizId,
+				BankId:         owner.BankId,
+				Version:        owner.Version,
+				Tag:            strings.TrimSpace(v.Tag),
+				CategoryId:     v.CategoryId,
+				QuestionText:   v.Text,
+				QuestionType:   v.Type,
+				ScoringMode:    v.ScoringMode,
+				Points:         v.Points,
+				NegativePoints: v.NegativePoints,
+				AnswerFormula:  v.Formula,
+			})
+		if err != nil {
+			return err
+		}
+		if err = types[i].Save(ctx, &questions[i]); err != nil {
+			return err
+		}
+		if err = addQuestionVariables(ctx, &questions[i]); err != nil {
+			return err
+		}
+		if err = addQuestionExplanation(ctx, &questions[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Loads questions of the quiz version together with their right answers.
+func getQuizQuestionsWithAnswers(ctx context.Context, quizId int32, version int32) ([]Question, error) {
+	questionModels, err := repository.QuizRepositoryInstance.
+		GetQuizVersionQuestions(ctx, quizId, version)
+	if err != nil {
+		return nil, err
+	}
+	return loadQuestionsWithAnswers(ctx, questionModels)
+}
+
+func loadQuestionsWithAnswers(ctx context.Context, questionModels []*models.Question) ([]Question, error) {
+	questions := make([]Question, len(questionModels))
+	for i, v := range questionModels {
+		questions[i].Id = v.Id
+		questions[i].Text = v.QuestionText
+		questions[i].Type = v.QuestionType
+		questions[i].Tag = v.Tag
+		questions[i].CategoryId = v.CategoryId
+		questions[i].ScoringMode = v.ScoringMode
+		questions[i].Points = v.Points
+		questions[i].NegativePoints = v.NegativePoints
+		questions[i].Formula = v.AnswerFormula
+
+		questionType, err := getQuestionType(v.QuestionType)
+		if err != nil {
+			return nil, err
+		}
+		if err = questionType.Load(ctx, v, &questions[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadQuestionVariables(ctx, questions); err != nil {
+		return nil, err
+	}
+
+	questionIds := make([]int32, len(questions))
+	for i, q := range questions {
+		questionIds[i] = q.Id
+	}
+	explanations, err := questionExplanations(ctx, questionIds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range questions {
+		questions[i].Explanation = explanations[questions[i].Id]
+	}
+	return questions, nil
+}
+
 func QuizCreatePostHandler(c *gin.Context) {
 
 	var (
@@ -85,110 +416,368 @@ func QuizCreatePostHandler(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	sessionData, _ := data.(*middleware.SessionData)
+	if sessionData != nil {
+		authorId = sessionData.UserId
+	}
+	if err := c.ShouldBindJSON(&quiz); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	categoryIds, err := parseCategoryIds(quiz.Categories)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err = validateQuizWindow(&quiz); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
+
+		quizId, err := repository.QuizRepositoryInstance.
+			AddQuiz(ctx, quiz.Title, quiz.Description, authorId)
+		if err != nil {
+			return err
+		}
+
+		err = repository.QuizRepositoryInstance.
+			AddQuizVersion(ctx, quizId, 1, quiz.Title, quiz.Description)
+		if err != nil {
+			return err
+		}
+
+		err = repository.QuizRepositoryInstance.
+			UpdateQuizSettings(ctx, quizSettings(quizId, &quiz))
+		if err != nil {
+			return err
+		}
+
+		err = repository.QuizRepositoryInstance.
+			AddQuizCategories(ctx, quizId, categoryIds)
+		if err != nil {
+			return err
+		}
+
+		return addQuizQuestions(ctx, sessionData, quizId, 1, &quiz)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/quiz")
+}
+
+func QuizCreateFormGetHandler(c *gin.Context) {
+	var sessionData *middleware.SessionData
+	data, ok := c.Get("sessionData")
+	if ok {
+		sessionData, _ = data.(*middleware.SessionData)
+	}
+
+	ctx := context.Background()
+	categories, err := repository.QuizRepositoryInstance.GetAllCategories(ctx)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	banks, err := availableBanks(ctx, sessionData)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	baseHInterface, _ := c.Get("BaseH")
+	baseH, _ := baseHInterface.(*gin.H)
+	c.HTML(http.StatusOK, "quiz_form.html", utility.MergeMaps(*baseH, gin.H{
+		"title":      "Quizzes",
+		"categories": categories,
+		"banks":      banks,
+		"quiz":       nil,
+		"action":     "/quiz/create"}))
+}
+
+func QuizEditFormGetHandler(c *gin.Context) {
+	var sessionData *middleware.SessionData
+	data, ok := c.Get("sessionData")
+	if ok {
+		sessionData, _ = data.(*middleware.SessionData)
+	}
+
+	i, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	id := int32(i)
+
+	ctx := context.Background()
+	quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !canEditQuiz(sessionData, quizModel) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not enough permissions"})
+		return
+	}
+
+	categories, err := repository.QuizRepositoryInstance.GetAllCategories(ctx)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, categoryIds, err := repository.QuizRepositoryInstance.
+		GetCategoriesPairs(ctx, []int32{id})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quiz := Quiz{
+		Id:          quizModel.Id,
+		Title:       quizModel.Title,
+		Categories:  make([]string, len(categoryIds)),
+		ScorePolicy: quizModel.ScorePolicy,
+
+		ShuffleQuestions: quizModel.ShuffleQuestions,
+		ShuffleChoices:   quizModel.ShuffleChoices,
+		Status:           quizModel.Status,
+		OpensAt:          quizModel.OpensAt,
+		ClosesAt:         quizModel.ClosesAt,
+		RevealPolicy:     quizModel.RevealPolicy,
+	}
+	if quizModel.Description != nil {
+		quiz.Description = *quizModel.Description
+	}
+	if quizModel.TimeLimitSeconds != nil {
+		quiz.TimeLimit = *quizModel.TimeLimitSeconds
+	}
+	if quizModel.MaxAttempts != nil {
+		quiz.MaxAttempts = *quizModel.MaxAttempts
+	}
+	if quizModel.CooldownSeconds != nil {
+		quiz.Cooldown = *quizModel.CooldownSeconds
+	}
+	if quizModel.PassMark != nil {
+		quiz.PassMark = roundValue(*quizModel.PassMark * 100)
+	}
+	for i, v := range categoryIds {
+		quiz.Categories[i] = strconv.FormatInt(int64(v), 10)
+	}
+
+	quiz.Questions, err = getQuizQuestionsWithAnswers(ctx, id, quizModel.Version)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	quiz.DrawRules, err = getDrawRules(ctx, id, quizModel.Version)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	banks, err := availableBanks(ctx, sessionData)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	baseHInterface, _ := c.Get("BaseH")
+	baseH, _ := baseHInterface.(*gin.H)
+	c.HTML(http.StatusOK, "quiz_form.html", utility.MergeMaps(*baseH, gin.H{
+		"title":      "Quizzes",
+		"categories": categories,
+		"banks":      banks,
+		"quiz":       quiz,
+		"action":     fmt.Sprintf("/quiz/%d/edit", id)}))
+}
+
+// Editing a quiz creates a new quiz version, questions of the previous
+// versions are kept so that stored answers and results stay valid.
+func QuizEditPostHandler(c *gin.Context) {
+	var (
+		sessionData *middleware.SessionData
+		quiz        Quiz
+	)
+	data, ok := c.Get("sessionData")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+	sessionData, _ = data.(*middleware.SessionData)
+
+	i, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	id := int32(i)
+
+	if err := c.ShouldBindJSON(&quiz); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	categoryIds, err := parseCategoryIds(quiz.Categories)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err = validateQuizWindow(&quiz); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !canEditQuiz(sessionData, quizModel) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not enough permissions"})
+		return
+	}
+
+	err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
+
+		version, err := repository.QuizRepositoryInstance.
+			EditQuiz(ctx, id, quiz.Title, quiz.Description)
+		if err != nil {
+			return err
+		}
+
+		err = repository.QuizRepositoryInstance.
+			AddQuizVersion(ctx, id, version, quiz.Title, quiz.Description)
+		if err != nil {
+			return err
+		}
+
+		err = repository.QuizRepositoryInstance.
+			UpdateQuizSettings(ctx, quizSettings(id, &quiz))
+		if err != nil {
+			return err
+		}
+
+		err = repository.QuizRepositoryInstance.RemoveQuizCategories(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = repository.QuizRepositoryInstance.
+			AddQuizCategories(ctx, id, categoryIds)
+		if err != nil {
+			return err
+		}
+
+		return addQuizQuestions(ctx, sessionData, id, version, &quiz)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/quiz")
+}
+
+func QuizParticipationPostHandler(c *gin.Context) {
+
+	var (
+		userId     int32
+		quizId     int32
+		submission Submission
+		err        error
+	)
+	data, ok := c.Get("sessionData")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
 	if sessionData, ok := data.(*middleware.SessionData); ok {
-		authorId = sessionData.UserId
+		userId = sessionData.UserId
 	}
-	if err := c.ShouldBindJSON(&quiz); err != nil {
+	if err := c.ShouldBindJSON(&submission); err != nil {
+		println(err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
-	categoryIds := make([]int32, len(quiz.Categories))
-	for i, v := range quiz.Categories {
-		categoryId, err := strconv.ParseInt(v, 10, 32)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		categoryIds[i] = int32(categoryId)
-	}
-	if len(categoryIds) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category"})
+	quizId = submission.QuizId
+
+	questionMap, err := submissionAnswers(&submission)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx := context.Background()
-	err := repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
+	expired := false
+	err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
 
-		quizId, err := repository.QuizRepositoryInstance.
-			AddQuiz(ctx, quiz.Title, quiz.Description, authorId)
+		partTime, err := getOpenAttempt(ctx, userId, submission.AttemptId, quizId)
 		if err != nil {
 			return err
 		}
 
-		err = repository.QuizRepositoryInstance.
-			AddQuizCategories(ctx, quizId, categoryIds)
+		quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, quizId)
 		if err != nil {
 			return err
 		}
 
-		if len(quiz.Questions) == 0 {
-			return fmt.Errorf("invalid question count")
+		// Late submissions are dropped, the attempt is closed at its
+		// deadline with the answers saved before.
+		now := time.Now().UTC()
+		if err = checkQuizWindow(quizModel, now, attemptGrace()); err != nil {
+			return err
 		}
-
-		for i, v := range quiz.Questions {
-			quiz.Questions[i].Id, err = repository.QuizRepositoryInstance.
-				AddQuestion(ctx, quizId, v.Text, v.Type)
-			if err != nil {
-				return err
-			}
+		deadline := attemptDeadline(quizModel, partTime)
+		if isAttemptExpired(deadline, now) {
+			expired = true
+			err = finishAttempt(ctx, quizModel, partTime, nil, *deadline)
+		} else {
+			err = finishAttempt(ctx, quizModel, partTime, questionMap, now)
 		}
-
-		for _, v := range quiz.Questions {
-			if v.Type == "text" {
-				_, err = repository.QuizRepositoryInstance.
-					AddTextQuestionAnswer(ctx, v.Id, v.RightAnswer)
-				if err != nil {
-					return err
-				}
-			} else {
-				if len(v.Choices) == 0 {
-					return fmt.Errorf("invalid choice count")
-				}
-				for _, c := range v.Choices {
-					_, err = repository.QuizRepositoryInstance.
-						AddChoice(ctx, v.Id, c.Text, c.IsCorrect)
-					if err != nil {
-						return err
-					}
-				}
-			}
+		if err != nil {
+			return err
 		}
 
-		return nil
+		return issueCertificate(ctx, partTime.Id, now)
 	})
-	if err != nil {
+	if _, ok := err.(*apperrors.ErrPermissionDenied); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		println(err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	c.Redirect(http.StatusFound, "/quiz")
-}
-
-func QuizCreateFormGetHandler(c *gin.Context) {
-	ctx := context.Background()
-	categories, err := repository.QuizRepositoryInstance.GetAllCategories(ctx)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if expired {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "time limit exceeded"})
 		return
 	}
 
-	baseHInterface, _ := c.Get("BaseH")
-	baseH, _ := baseHInterface.(*gin.H)
-	c.HTML(http.StatusOK, "quiz_form.html", utility.MergeMaps(*baseH, gin.H{
-		"title":      "Quizzes",
-		"categories": categories}))
+	c.Redirect(http.StatusFound, "/quiz")
 }
 
-func QuizParticipationPostHandler(c *gin.Context) {
+// Saves the answers given so far, the attempt stays open.
+func QuizParticipationSavePostHandler(c *gin.Context) {
 
 	var (
 		userId     int32
-		quizId     int32
 		submission Submission
-		err        error
 	)
 	data, ok := c.Get("sessionData")
 	if !ok {
@@ -200,110 +789,66 @@ func QuizParticipationPostHandler(c *gin.Context) {
 		userId = sessionData.UserId
 	}
 	if err := c.ShouldBindJSON(&submission); err != nil {
-		println(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	quizId = submission.QuizId
-
-	questionMap := make(map[int32]string)
-	for key, value := range submission.Answers {
-		questionId, err := strconv.ParseInt(key, 10, 32)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		questionMap[int32(questionId)] = value
+	questionMap, err := submissionAnswers(&submission)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	ctx := context.Background()
+	expired := false
 	err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
 
-		partTime, err := repository.QuizRepositoryInstance.GetLastParticipationTime(ctx, userId)
-		if _, ok := err.(*apperrors.ErrNotFound); ok {
-			return fmt.Errorf("no participation")
-		} else if err == nil {
-			if partTime.QuizId != int32(quizId) {
-				println(quizId)
-				println(partTime.QuizId)
-				return fmt.Errorf("invalid quiz")
-			}
-			err := repository.QuizRepositoryInstance.UpdateParticipationTime(ctx, partTime.Id, time.Now().UTC())
-			if err != nil {
-				return err
-			}
-		} else {
+		partTime, err := getOpenAttempt(ctx, userId, submission.AttemptId, submission.QuizId)
+		if err != nil {
 			return err
 		}
 
-		questionModels, err := repository.QuizRepositoryInstance.
-			GetQuizQuestions(ctx, int32(quizId))
+		quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, submission.QuizId)
 		if err != nil {
 			return err
 		}
-		amountOfQuestions := float32(len(questionModels))
-		rightAnswers := float32(0)
-
-		for _, q := range questionModels {
-			if answ, ok := questionMap[q.Id]; ok {
-				if q.QuestionType != "text" {
-					choiceId, err := strconv.ParseInt(answ, 10, 32)
-					if err != nil {
-						return err
-					}
-					correctChoice, err := repository.QuizRepositoryInstance.GetCorrectChoice(ctx, q.Id)
-					if err != nil {
-						return err
-					}
-					if correctChoice.Id == int32(choiceId) {
-						rightAnswers += 1
-					}
-					err = repository.QuizRepositoryInstance.RemoveUserChoiceAnswers(ctx, q.Id, userId)
-					if err != nil {
-						return err
-					}
-					err = repository.QuizRepositoryInstance.AddUserChoiceAnswer(ctx, userId, q.Id, int32(choiceId))
-					if err != nil {
-						return err
-					}
-				} else {
-					correctText, err := repository.QuizRepositoryInstance.GetTextQuestionAnswer(ctx, q.Id)
-					if err != nil {
-						return err
-					}
-					if correctText.RightAnswer == answ {
-						rightAnswers += 1
-					}
-					err = repository.QuizRepositoryInstance.RemoveUserTextAnswers(ctx, q.Id, userId)
-					if err != nil {
-						return err
-					}
-					err = repository.QuizRepositoryInstance.AddUserTextAnswer(ctx, userId, q.Id, answ)
-					if err != nil {
-						return err
-					}
-				}
-			}
-		}
-
-		err = repository.QuizRepositoryInstance.
-			UpsertUserScore(ctx, userId, int32(quizId), rightAnswers/amountOfQuestions, time.Now().UTC())
-		if err != nil {
+		now := time.Now().UTC()
+		if err = checkQuizWindow(quizModel, now, attemptGrace()); err != nil {
 			return err
 		}
+		deadline := attemptDeadline(quizModel, partTime)
+		if isAttemptExpired(deadline, now) {
+			expired = true
+			return finishAttempt(ctx, quizModel, partTime, nil, *deadline)
+		}
 
-		return nil
+		return saveAnswers(ctx, partTime, questionMap)
 	})
-	if err != nil {
-		println(err.Error())
+	if _, ok := err.(*apperrors.ErrPermissionDenied); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if expired {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "time limit exceeded"})
+		return
+	}
 
-	c.Redirect(http.StatusFound, "/quiz")
+	c.JSON(http.StatusOK, gin.H{"status": "saved"})
+}
+
+func submissionAnswers(submission *Submission) (map[int32]Answer, error) {
+	questionMap := make(map[int32]Answer)
+	for key, value := range submission.Answers {
+		questionId, err := strconv.ParseInt(key, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		questionMap[int32(questionId)] = value
+	}
+	return questionMap, nil
 }
 
 func QuizParticipationFormGetHandler(c *gin.Context) {
@@ -325,77 +870,97 @@ func QuizParticipationFormGetHandler(c *gin.Context) {
 	ctx := context.Background()
 
 	var quiz Quiz
+	expired := false
 	err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
 
-		partTime, err := repository.QuizRepositoryInstance.GetLastParticipationTime(ctx, userId)
-		if _, ok := err.(*apperrors.ErrNotFound); ok {
-			_, err := repository.QuizRepositoryInstance.AddParticipationTime(ctx, userId, id, time.Now().UTC())
+		quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err = checkQuizWindow(quizModel, time.Now().UTC(), 0); err != nil {
+			return err
+		}
+
+		partTime, err := repository.QuizRepositoryInstance.GetOpenParticipationTime(ctx, userId, id)
+		if err == nil {
+			if deadline := attemptDeadline(quizModel, partTime); isAttemptExpired(deadline, time.Now().UTC()) {
+				expired = true
+				return finishAttempt(ctx, quizModel, partTime, nil, *deadline)
+			}
+		} else if _, ok := err.(*apperrors.ErrNotFound); ok {
+			err := startAttempt(ctx, quizModel, userId, time.Now().UTC())
 			if err != nil {
 				return err
 			}
-		} else if err == nil {
-			if partTime.FinishedAt == nil && partTime.QuizId != id {
-				err := repository.QuizRepositoryInstance.DeleteParticipationTime(ctx, partTime.Id)
-				if err != nil {
-					return err
-				}
-				_, err = repository.QuizRepositoryInstance.AddParticipationTime(ctx, userId, id, time.Now().UTC())
-				if err != nil {
-					return err
-				}
-			} else if partTime.FinishedAt != nil {
-				_, err = repository.QuizRepositoryInstance.AddParticipationTime(ctx, userId, id, time.Now().UTC())
-				if err != nil {
-					return err
-				}
+			partTime, err = repository.QuizRepositoryInstance.GetOpenParticipationTime(ctx, userId, id)
+			if err != nil {
+				return err
 			}
 		} else {
 			return err
 		}
+		quiz.AttemptId = partTime.Id
+		quiz.SavedAnswers, err = loadSavedAnswers(ctx, partTime)
+		if err != nil {
+			return err
+		}
 
-		quizModel, err := repository.QuizRepositoryInstance.
-			GetQuiz(ctx, id)
+		// The attempt may have been started on an older quiz version.
+		quizVersion, err := repository.QuizRepositoryInstance.
+			GetQuizVersion(ctx, id, partTime.QuizVersion)
 		if err != nil {
 			return err
 		}
-		quiz.Id = quizModel.Id
-		quiz.Title = quizModel.Title
-		quiz.Description = *quizModel.Description
+		quiz.Id = quizVersion.QuizId
+		quiz.Title = quizVersion.Title
+		quiz.Description = *quizVersion.Description
+		if deadline := attemptDeadline(quizModel, partTime); deadline != nil {
+			quiz.TimeLimit = *quizModel.TimeLimitSeconds
+			quiz.RemainingSeconds = int32(math.Max(0, math.Ceil(time.Until(*deadline).Seconds())))
+		}
 
-		questionModels, err := repository.QuizRepositoryInstance.
-			GetQuizQuestions(ctx, id)
+		questionModels, err := attemptQuestions(ctx, partTime)
 		if err != nil {
 			return err
 		}
+		shuffleAttemptQuestions(partTime, questionModels)
 		quiz.Questions = make([]Question, len(questionModels))
 
 		for i, v := range questionModels {
 			quiz.Questions[i].Id = v.Id
-			quiz.Questions[i].Text = v.QuestionText
 			quiz.Questions[i].Type = v.QuestionType
+			quiz.Questions[i].Text, err = attemptQuestionText(ctx, partTime.Id, v)
+			if err != nil {
+				return err
+			}
 
-			if v.QuestionType != "text" {
-				choiceModels, err := repository.QuizRepositoryInstance.
-					GetChoices(ctx, v.Id)
-				if err != nil {
-					return err
-				}
-				quiz.Questions[i].Choices = make([]Choice, len(choiceModels))
-				for j, choice := range choiceModels {
-					quiz.Questions[i].Choices[j].Id = choice.Id
-					quiz.Questions[i].Choices[j].Text = choice.ChoiceText
-					quiz.Questions[i].Choices[j].QuestionId = v.Id
-				}
+			questionType, err := getQuestionType(v.QuestionType)
+			if err != nil {
+				return err
+			}
+			if err = questionType.Render(ctx, partTime.Id, v, &quiz.Questions[i]); err != nil {
+				return err
 			}
+			choices := quiz.Questions[i].Choices
+			shuffleAttemptChoices(partTime, v.Id, len(choices), func(a, b int) {
+				choices[a], choices[b] = choices[b], choices[a]
+			})
 		}
 
 		return nil
 	})
-	if err != nil {
+	if _, ok := err.(*apperrors.ErrPermissionDenied); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		println(err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if expired {
+		c.Redirect(http.StatusFound, fmt.Sprintf("/quiz/%d/result", id))
+		return
+	}
 
 	baseHInterface, _ := c.Get("BaseH")
 	baseH, _ := baseHInterface.(*gin.H)
@@ -405,6 +970,12 @@ func QuizParticipationFormGetHandler(c *gin.Context) {
 }
 
 func QuizIndexGetHandler(c *gin.Context) {
+	var sessionData *middleware.SessionData
+	data, ok := c.Get("sessionData")
+	if ok {
+		sessionData, _ = data.(*middleware.SessionData)
+	}
+
 	ctx := context.Background()
 	categories, err := repository.QuizRepositoryInstance.GetAllCategories(ctx)
 	if err != nil {
@@ -426,7 +997,13 @@ func QuizIndexGetHandler(c *gin.Context) {
 		categoryId = int32(i)
 	}
 
-	quizzes, err := repository.QuizRepositoryInstance.GetAllQuizzes(ctx, categoryId)
+	viewerId, showHidden := int32(0), false
+	if sessionData != nil {
+		viewerId = sessionData.UserId
+		showHidden = sessionData.Permissions&models.MANAGE_QUIZZES_PERM != 0
+	}
+	quizzes, err := repository.QuizRepositoryInstance.
+		GetAllQuizzes(ctx, categoryId, viewerId, showHidden)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -434,8 +1011,11 @@ func QuizIndexGetHandler(c *gin.Context) {
 	frontQuizzes := make([]Quiz, 0, len(quizzes))
 	quizIds := make([]int32, 0, len(quizzes))
 	quizMap := make(map[int32]*Quiz)
+	quizModels := make(map[int32]*models.Quiz)
+	now := time.Now().UTC()
 	for _, q := range quizzes {
 		quizIds = append(quizIds, q.Id)
+		quizModels[q.Id] = q
 		frontQuizzes = append(frontQuizzes, Quiz{
 			Id:           q.Id,
 			Title:        q.Title,
@@ -443,7 +1023,20 @@ func QuizIndexGetHandler(c *gin.Context) {
 			AverageTime:  "00:00:00",
 			AverageScore: "0%",
 			Categories:   make([]string, 0),
+			CanEdit:      canEditQuiz(sessionData, q),
+			ScorePolicy:  q.ScorePolicy,
+			Status:       q.Status,
+			OpensAt:      q.OpensAt,
+			ClosesAt:     q.ClosesAt,
+			IsOpen:       isQuizOpen(q, now),
 		})
+		if q.TimeLimitSeconds != nil {
+			frontQuizzes[len(frontQuizzes)-1].TimeLimit = *q.TimeLimitSeconds
+		}
+		if q.MaxAttempts != nil {
+			frontQuizzes[len(frontQuizzes)-1].MaxAttempts = *q.MaxAttempts
+			frontQuizzes[len(frontQuizzes)-1].AttemptsLeft = strconv.FormatInt(int64(*q.MaxAttempts), 10)
+		}
 		quizMap[q.Id] = &frontQuizzes[len(frontQuizzes)-1]
 	}
 
@@ -467,6 +1060,42 @@ func QuizIndexGetHandler(c *gin.Context) {
 		qp.AverageScore = fmt.Sprintf("%.2f%%", s.AverageScore*100)
 		qp.AverageTime = *s.AverageCompletionTime
 		qp.TotalAttempts = int32(s.TotalAttempts)
+		if s.PassRate != nil {
+			qp.PassRate = fmt.Sprintf("%.2f%%", *s.PassRate*100)
+		}
+	}
+
+	if sessionData != nil {
+		userScores, err := repository.QuizRepositoryInstance.
+			GetUserScores(ctx, sessionData.UserId, quizIds)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for _, s := range userScores {
+			quizMap[s.QuizId].UserScore = fmt.Sprintf("%s pts (%.2f%%)",
+				formatPoints(s.Points, s.MaxPoints), s.Score*100)
+			if s.Provisional {
+				quizMap[s.QuizId].UserScore += ", provisional"
+			}
+			if passed := isPassed(quizModels[s.QuizId], s.Score); passed != nil {
+				quizMap[s.QuizId].IsPassed = *passed
+			}
+		}
+
+		participations, err := repository.QuizRepositoryInstance.
+			GetUserParticipations(ctx, sessionData.UserId, quizIds)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for _, p := range participations {
+			qp := quizMap[p.QuizId]
+			if qp.MaxAttempts > 0 {
+				left := max(0, qp.MaxAttempts-int32(p.ParticipationCount))
+				qp.AttemptsLeft = strconv.FormatInt(int64(left), 10)
+			}
+		}
 	}
 
 	baseHInterface, _ := c.Get("BaseH")
@@ -480,8 +1109,9 @@ func QuizIndexGetHandler(c *gin.Context) {
 
 func QuizResultGetHandler(c *gin.Context) {
 	var (
-		userId int32
-		quizId int32
+		sessionData *middleware.SessionData
+		userId      int32
+		quizId      int32
 	)
 	data, ok := c.Get("sessionData")
 	if !ok {
@@ -489,7 +1119,7 @@ func QuizResultGetHandler(c *gin.Context) {
 		c.Abort()
 		return
 	}
-	if sessionData, ok := data.(*middleware.SessionData); ok {
+	if sessionData, ok = data.(*middleware.SessionData); ok {
 		userId = sessionData.UserId
 	}
 	i, err := strconv.ParseInt(c.Param("id"), 10, 32)
@@ -499,83 +1129,145 @@ func QuizResultGetHandler(c *gin.Context) {
 	}
 	quizId = int32(i)
 
+	// The latest finished attempt is shown unless another one is requested.
 	ctx := context.Background()
-	userScore, err := repository.QuizRepositoryInstance.
-		GetUserScore(ctx, userId, quizId)
-	if _, ok := err.(*apperrors.ErrNotFound); ok {
-		c.Redirect(http.StatusFound, "/quiz")
-	} else if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	var quizPartModel *models.QuizParticipationTime
+	if c.Query("attempt") != "" {
+		i, err := strconv.ParseInt(c.Query("attempt"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		quizPartModel, err = repository.QuizRepositoryInstance.
+			GetParticipationTime(ctx, int32(i))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if quizPartModel.UserId != userId || quizPartModel.QuizId != quizId ||
+			quizPartModel.FinishedAt == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "attempt not found"})
+			return
+		}
+	} else {
+		quizPartModel, err = repository.QuizRepositoryInstance.
+			GetQuizParticipationTime(ctx, userId, quizId)
+		if _, ok := err.(*apperrors.ErrNotFound); ok {
+			c.Redirect(http.StatusFound, "/quiz")
+			return
+		} else if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 	}
 
-	quizModel, err := repository.QuizRepositoryInstance.
-		GetQuiz(ctx, quizId)
+	// Results are rendered against the quiz version that was answered.
+	quizVersion, err := repository.QuizRepositoryInstance.
+		GetQuizVersion(ctx, quizId, quizPartModel.QuizVersion)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	quizPartModel, err := repository.QuizRepositoryInstance.
-		GetQuizParticipationTime(ctx, userId, quizId)
+	quizResult := QuizResult{
+		QuizId:      quizId,
+		Number:      quizPartModel.ParticipationNumber,
+		Title:       quizVersion.Title,
+		Description: *quizVersion.Description,
+		Time:        formatDuration(quizPartModel.FinishedAt.Sub(quizPartModel.StartedAt)),
+	}
+
+	questionModels, err := attemptQuestions(ctx, quizPartModel)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	shuffleAttemptQuestions(quizPartModel, questionModels)
+	quizResult.Questions = make([]AnsweredQuestion, len(questionModels))
 
-	quizResult := QuizResult{
-		Title:       quizModel.Title,
-		Description: *quizModel.Description,
-		Score:       fmt.Sprintf("%.2f%%", userScore.Score*100),
-		Time:        formatDuration(quizPartModel.FinishedAt.Sub(quizPartModel.StartedAt)),
+	questionIds := make([]int32, len(questionModels))
+	for i, v := range questionModels {
+		questionIds[i] = v.Id
 	}
-
-	questionModels, err := repository.QuizRepositoryInstance.
-		GetQuizQuestions(ctx, quizId)
+	explanations, err := questionExplanations(ctx, questionIds)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	quizResult.Questions = make([]AnsweredQuestion, len(questionModels))
 
+	points := float64(0)
+	maxPoints := float64(0)
 	for i, v := range questionModels {
-		quizResult.Questions[i].Text = v.QuestionText
 		quizResult.Questions[i].Type = v.QuestionType
+		quizResult.Questions[i].Explanation = explanations[v.Id]
+		quizResult.Questions[i].Text, err = attemptQuestionText(ctx, quizPartModel.Id, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-		if v.QuestionType != "text" {
-			correctChoice, err := repository.QuizRepositoryInstance.GetCorrectChoice(ctx, v.Id)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-			userChoice, err := repository.QuizRepositoryInstance.GetUserChoiceAnswer(ctx, userId, v.Id)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-			choice, err := repository.QuizRepositoryInstance.GetChoice(ctx, *userChoice.ChoiceId)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-			quizResult.Questions[i].IsCorrect = correctChoice.Id == *userChoice.ChoiceId
-			quizResult.Questions[i].RightAnswer = correctChoice.ChoiceText
-			quizResult.Questions[i].UserAnswer = choice.ChoiceText
-		} else {
-			correctText, err := repository.QuizRepositoryInstance.GetTextQuestionAnswer(ctx, v.Id)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
+		questionType, err := getQuestionType(v.QuestionType)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		credit, answered, err := questionType.
+			DescribeResult(ctx, quizPartModel.Id, v, &quizResult.Questions[i])
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		selections := quizResult.Questions[i].Selections
+		shuffleAttemptChoices(quizPartModel, v.Id, len(selections), func(a, b int) {
+			selections[a], selections[b] = selections[b], selections[a]
+		})
+		earned := float64(questionPoints(v, credit, answered))
+		points += earned
+		maxPoints += v.Points
+		quizResult.Questions[i].IsCorrect = credit == 1
+		quizResult.Questions[i].Points = formatPoints(earned, v.Points)
+		if quizResult.Questions[i].IsPending {
+			quizResult.IsProvisional = true
+		}
+	}
 
-			userText, err := repository.QuizRepositoryInstance.GetUserTextAnswer(ctx, userId, v.Id)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-			quizResult.Questions[i].IsCorrect = *userText.TextAnswer == correctText.RightAnswer
-			quizResult.Questions[i].RightAnswer = correctText.RightAnswer
-			quizResult.Questions[i].UserAnswer = *userText.TextAnswer
+	// Attempts without a stored score are scored from their answers.
+	score := float64(0)
+	if quizPartModel.Score != nil {
+		score, points, maxPoints = *quizPartModel.Score, *quizPartModel.Points, *quizPartModel.MaxPoints
+	} else if maxPoints > 0 && points > 0 {
+		score = points / maxPoints
+	}
+	quizResult.Score = fmt.Sprintf("%.2f%%", score*100)
+	quizResult.Points = formatPoints(points, maxPoints)
+
+	// Editors always see the answers of their quizzes.
+	quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, quizId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	visibility := RESULT_ANSWERS
+	if !canEditQuiz(sessionData, quizModel) {
+		visibility, err = resultVisibility(ctx, quizModel, userId, time.Now().UTC())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	hideResult(&quizResult, visibility)
+
+	// Attempts scored before the quiz got its pass mark are checked
+	// against the current one.
+	if quizModel.PassMark != nil {
+		passed := quizPartModel.Passed
+		if passed == nil {
+			passed = isPassed(quizModel, score)
+		}
+		quizResult.PassMark = fmt.Sprintf("%.2f%%", *quizModel.PassMark*100)
+		quizResult.Outcome = "Failed"
+		if *passed {
+			quizResult.Outcome = "Passed"
 		}
 	}
 
@@ -586,6 +1278,62 @@ func QuizResultGetHandler(c *gin.Context) {
 		"quiz":  quizResult}))
 }
 
+func QuizAttemptsGetHandler(c *gin.Context) {
+	var userId int32
+	data, ok := c.Get("sessionData")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+	if sessionData, ok := data.(*middleware.SessionData); ok {
+		userId = sessionData.UserId
+	}
+	i, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	quizId := int32(i)
+
+	ctx := context.Background()
+	quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, quizId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	partTimes, err := repository.QuizRepositoryInstance.
+		GetUserParticipationTimes(ctx, userId, quizId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	attempts := make([]Attempt, len(partTimes))
+	for i, v := range partTimes {
+		attempts[i] = Attempt{
+			Id:         v.Id,
+			Number:     v.ParticipationNumber,
+			StartedAt:  v.StartedAt,
+			IsFinished: v.FinishedAt != nil,
+		}
+		if v.FinishedAt != nil {
+			attempts[i].Time = formatDuration(v.FinishedAt.Sub(v.StartedAt))
+		}
+		if v.Score != nil {
+			attempts[i].Score = fmt.Sprintf("%.2f%%", *v.Score*100)
+			attempts[i].Points = formatPoints(*v.Points, *v.MaxPoints)
+		}
+	}
+
+	baseHInterface, _ := c.Get("BaseH")
+	baseH, _ := baseHInterface.(*gin.H)
+	c.HTML(http.StatusOK, "quiz_attempts.html", utility.MergeMaps(*baseH, gin.H{
+		"title":    "Quiz Attempts",
+		"quiz":     quizModel,
+		"attempts": attempts}))
+}
+
 func QuizDeletePostHandler(c *gin.Context) {
 	i, err := strconv.ParseInt(c.Param("id"), 10, 32)
 	if err != nil {
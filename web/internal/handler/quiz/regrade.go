@@ -0,0 +1,250 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/middleware"
+	"quiz_platform/internal/misc/apperrors"
+	"quiz_platform/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RegradeRequest struct {
+	QuestionId int32 `json:"question_id" binding:"min=0"`
+}
+
+// Corrected answer key of a question. Choices are numbered from 1 in the
+// order the editor shows them, the other fields are the same as in Question.
+type QuestionKeyRequest struct {
+	CorrectChoices []int            `json:"correct_choices,omitempty"`
+	RightAnswers   []TextAnswerRule `json:"right_answers,omitempty" binding:"dive"`
+	Numeric        *NumericAnswer   `json:"numeric,omitempty"`
+}
+
+// Result of regrading the finished attempts.
+type RegradeResult struct {
+	Attempts int `json:"attempts"`
+	Changed  int `json:"changed"`
+	Revoked  int `json:"revoked"`
+}
+
+// Grades the answers stored in the finished attempts of the quiz again with
+// the current answer keys, updates the scores and the quiz statistics,
+// revokes the certificates of attempts which no longer pass and records
+// the action of the user. Non-zero question id regrades only the
+// attempts which received that question, zero quiz id the attempts of all
+// quizzes. Nil user id stands for an administrator outside of the site.
+func Regrade(ctx context.Context, quizId int32, questionId int32, userId *int32) (*RegradeResult, error) {
+	attempts, err := repository.QuizRepositoryInstance.GetFinishedAttempts(ctx, quizId, questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	result := &RegradeResult{Attempts: len(attempts)}
+	quizModels := make(map[int32]*models.Quiz)
+	for _, partTime := range attempts {
+		quizModel, ok := quizModels[partTime.QuizId]
+		if !ok {
+			quizModel, err = repository.QuizRepositoryInstance.GetQuiz(ctx, partTime.QuizId)
+			if err != nil {
+				return nil, err
+			}
+			quizModels[partTime.QuizId] = quizModel
+		}
+
+		score, err := scoreAttempt(ctx, quizModel, partTime, now)
+		if err != nil {
+			return nil, err
+		}
+		if partTime.Score == nil || math.Abs(*partTime.Score-float64(score)) > 1e-6 {
+			result.Changed++
+		}
+		if passed := isPassed(quizModel, float64(score)); passed == nil || !*passed {
+			revoked, err := repository.QuizRepositoryInstance.RemoveAttemptCertificate(ctx, partTime.Id)
+			if err != nil {
+				return nil, err
+			}
+			if revoked {
+				result.Revoked++
+			}
+		}
+	}
+
+	// Certificates are issued after all revocations, so that another passed
+	// attempt of the user can take the place of a revoked one.
+	for _, partTime := range attempts {
+		if err = issueCertificate(ctx, partTime.Id, now); err != nil {
+			return nil, err
+		}
+	}
+
+	for id := range quizModels {
+		if err = repository.QuizRepositoryInstance.CalculateQuizStatistics(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	description := "Regraded all quizzes"
+	if quizId != 0 {
+		description = fmt.Sprintf("Regraded quiz #%d", quizId)
+	}
+	if questionId != 0 {
+		description += fmt.Sprintf(", question #%d", questionId)
+	}
+	description += fmt.Sprintf(": %d of %d attempt scores changed, %d certificates revoked",
+		result.Changed, result.Attempts, result.Revoked)
+	err = repository.ActionsRepositoryInstance.AddAction(ctx, userId, description)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func QuizRegradePostHandler(c *gin.Context) {
+	var request RegradeRequest
+	data, ok := c.Get("sessionData")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+	sessionData, _ := data.(*middleware.SessionData)
+
+	i, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	id := int32(i)
+
+	// The body is optional, without it the whole quiz is regraded.
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var result *RegradeResult
+	ctx := context.Background()
+	err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
+
+		quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !canEditQuiz(sessionData, quizModel) {
+			return &apperrors.ErrPermissionDenied{Message: "Not enough permissions"}
+		}
+
+		result, err = Regrade(ctx, id, request.QuestionId, &sessionData.UserId)
+		return err
+	})
+	if _, ok := err.(*apperrors.ErrPermissionDenied); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Corrects the answer key of a question of any version of the quiz in place
+// and regrades the attempts which received the question. Editing the quiz
+// creates a new version instead, which leaves the past attempts as they are.
+func QuestionKeyPostHandler(c *gin.Context) {
+	var request QuestionKeyRequest
+	data, ok := c.Get("sessionData")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+	sessionData, _ := data.(*middleware.SessionData)
+
+	quizId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	questionId, err := strconv.ParseInt(c.Param("question"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result *RegradeResult
+	ctx := context.Background()
+	err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
+
+		quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, int32(quizId))
+		if err != nil {
+			return err
+		}
+		if !canEditQuiz(sessionData, quizModel) {
+			return &apperrors.ErrPermissionDenied{Message: "Not enough permissions"}
+		}
+		q, err := repository.QuizRepositoryInstance.GetQuestion(ctx, int32(questionId))
+		if _, ok := err.(*apperrors.ErrNotFound); ok {
+			return fmt.Errorf("invalid question")
+		} else if err != nil {
+			return err
+		}
+		// Questions drawn from banks belong to no quiz, attempts of the quiz
+		// which received them were graded with their keys all the same.
+		if q.QuizId != quizModel.Id {
+			received, err := repository.QuizRepositoryInstance.HasAttemptQuestion(ctx, quizModel.Id, q.Id)
+			if err != nil {
+				return err
+			}
+			if !received {
+				return fmt.Errorf("invalid question")
+			}
+		}
+
+		questionType, err := getQuestionType(q.QuestionType)
+		if err != nil {
+			return err
+		}
+		corrector, ok := questionType.(keyCorrector)
+		if !ok {
+			return fmt.Errorf("answer key of %s questions can not be corrected", q.QuestionType)
+		}
+		if err = corrector.CorrectKey(ctx, q, &request); err != nil {
+			return err
+		}
+		err = repository.ActionsRepositoryInstance.AddAction(ctx, &sessionData.UserId,
+			fmt.Sprintf("Corrected answer key of quiz #%d, question #%d", quizModel.Id, q.Id))
+		if err != nil {
+			return err
+		}
+
+		result, err = Regrade(ctx, quizModel.Id, q.Id, &sessionData.UserId)
+		return err
+	})
+	if _, ok := err.(*apperrors.ErrPermissionDenied); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
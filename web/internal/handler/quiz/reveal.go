@@ -0,0 +1,109 @@
+package quiz
+
+import (
+	"context"
+	"time"
+
+	"quiz_platform/internal/models"
+)
+
+// Policies choosing when the participants see the right answers.
+const (
+	REVEAL_IMMEDIATELY        = "immediately"
+	REVEAL_AFTER_CLOSE        = "after_close"
+	REVEAL_AFTER_LAST_ATTEMPT = "after_last_attempt"
+	REVEAL_CORRECTNESS        = "correctness"
+	REVEAL_NEVER              = "never"
+)
+
+// Parts of a result shown to the participant, each level includes the
+// previous ones.
+const (
+	RESULT_SCORE = iota
+	RESULT_CORRECTNESS
+	RESULT_ANSWERS
+)
+
+// Returns true once the quiz stops accepting attempts for good.
+func isQuizClosed(quizModel *models.Quiz, now time.Time) bool {
+	if quizModel.Status == QUIZ_STATUS_CLOSED || quizModel.Status == QUIZ_STATUS_ARCHIVED {
+		return true
+	}
+	return quizModel.ClosesAt != nil && now.After(*quizModel.ClosesAt)
+}
+
+// Returns how much of the results the user may see by the reveal policy of
+// the quiz. Until the answers are revealed only the correctness is shown.
+func resultVisibility(ctx context.Context, quizModel *models.Quiz, userId int32, now time.Time) (int, error) {
+	switch quizModel.RevealPolicy {
+	case REVEAL_NEVER:
+		return RESULT_SCORE, nil
+	case REVEAL_CORRECTNESS:
+		return RESULT_CORRECTNESS, nil
+	case REVEAL_AFTER_CLOSE:
+		if isQuizClosed(quizModel, now) {
+			return RESULT_ANSWERS, nil
+		}
+		return RESULT_CORRECTNESS, nil
+	case REVEAL_AFTER_LAST_ATTEMPT:
+		if isQuizClosed(quizModel, now) {
+			return RESULT_ANSWERS, nil
+		}
+		if quizModel.MaxAttempts != nil {
+			count, err := attemptCount(ctx, userId, quizModel.Id)
+			if err != nil {
+				return 0, err
+			}
+			if count >= *quizModel.MaxAttempts {
+				return RESULT_ANSWERS, nil
+			}
+		}
+		return RESULT_CORRECTNESS, nil
+	}
+	return RESULT_ANSWERS, nil
+}
+
+// Removes the parts of the result the participant may not see yet.
+func hideResult(result *QuizResult, visibility int) {
+	result.ShowCorrectness = visibility >= RESULT_CORRECTNESS
+	result.ShowAnswers = visibility >= RESULT_ANSWERS
+	if result.ShowAnswers {
+		return
+	}
+
+	for i := range result.Questions {
+		q := &result.Questions[i]
+		q.RightAnswer = ""
+		q.Explanation = ""
+		q.AnswerFeedback = ""
+		// Unselected right choices would give the answer away.
+		for j := range q.Selections {
+			q.Selections[j].IsCorrect = q.Selections[j].IsCorrect && q.Selections[j].IsSelected
+			q.Selections[j].Feedback = ""
+		}
+		for j := range q.Matches {
+			q.Matches[j].RightAnswer = ""
+		}
+		for j := range q.Segments {
+			q.Segments[j].RightAnswer = ""
+		}
+		if result.ShowCorrectness {
+			continue
+		}
+
+		q.IsCorrect = false
+		q.Points = ""
+		for j := range q.Selections {
+			q.Selections[j].IsCorrect = false
+		}
+		for j := range q.Placements {
+			q.Placements[j].IsCorrect = false
+		}
+		for j := range q.Matches {
+			q.Matches[j].IsCorrect = false
+		}
+		for j := range q.Segments {
+			q.Segments[j].IsCorrect = false
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/middleware"
+	"quiz_platform/internal/misc/apperrors"
+	"quiz_platform/internal/models"
+	"quiz_platform/internal/utility"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Grade given by a reviewer to a manually graded answer.
+type ManualGradeForm struct {
+	Points   float64 `json:"points" binding:"min=0"`
+	Feedback string  `json:"feedback"`
+}
+
+// Answer in the manual grading queue.
+type Review struct {
+	AttemptId  int32
+	QuestionId int32
+	QuizTitle  string
+	Question   string
+	MaxPoints  float64
+	Username   string
+	Answer     string
+	FinishedAt time.Time
+}
+
+// Puts the answers to the manually graded questions of the attempt into
+// the grading queue.
+func queueManualGrades(ctx context.Context, partTime *models.QuizParticipationTime) error {
+	questionModels, err := attemptQuestions(ctx, partTime)
+	if err != nil {
+		return err
+	}
+	for _, q := range questionModels {
+		if q.ScoringMode != SCORING_MANUAL {
+			continue
+		}
+		questionType, err := getQuestionType(q.QuestionType)
+		if err != nil {
+			return err
+		}
+		answ, err := questionType.LoadAnswer(ctx, partTime.Id, q)
+		if err != nil {
+			return err
+		}
+		if answ == nil {
+			continue
+		}
+		err = repository.QuizRepositoryInstance.AddManualGrade(ctx, partTime.Id, q.Id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the credit given by the reviewer and whether the answer is
+// graded already. Fills the reviewer comment of the result.
+func manualCredit(ctx context.Context, attemptId int32, q *models.Question, dst *AnsweredQuestion) (float32, bool, error) {
+	grade, err := repository.QuizRepositoryInstance.GetManualGrade(ctx, attemptId, q.Id)
+	if _, ok := err.(*apperrors.ErrNotFound); ok || (err == nil && grade.Points == nil) {
+		dst.IsPending = true
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	if grade.Feedback != nil {
+		dst.Feedback = *grade.Feedback
+	}
+	if q.Points <= 0 {
+		return 0, true, nil
+	}
+	return float32(*grade.Points / q.Points), true, nil
+}
+
+// Reviewers see the answers to their own quizzes, quiz managers see all.
+func reviewerAuthorId(sessionData *middleware.SessionData) int32 {
+	if sessionData.Permissions&models.MANAGE_QUIZZES_PERM != 0 {
+		return 0
+	}
+	return sessionData.UserId
+}
+
+func ReviewIndexGetHandler(c *gin.Context) {
+	data, ok := c.Get("sessionData")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+	sessionData, _ := data.(*middleware.SessionData)
+
+	ctx := context.Background()
+	reviewModels, err := repository.QuizRepositoryInstance.
+		GetPendingReviews(ctx, reviewerAuthorId(sessionData))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reviews := make([]Review, len(reviewModels))
+	for i, v := range reviewModels {
+		reviews[i] = Review{
+			AttemptId:  v.AttemptId,
+			QuestionId: v.QuestionId,
+			QuizTitle:  v.QuizTitle,
+			Question:   v.QuestionText,
+			MaxPoints:  v.MaxPoints,
+			Username:   v.Username,
+			FinishedAt: v.FinishedAt,
+		}
+		if v.TextAnswer != nil {
+			reviews[i].Answer = *v.TextAnswer
+		}
+	}
+
+	baseHInterface, _ := c.Get("BaseH")
+	baseH, _ := baseHInterface.(*gin.H)
+	c.HTML(http.StatusOK, "review_list.html", utility.MergeMaps(*baseH, gin.H{
+		"title":   "Grading Queue",
+		"reviews": reviews}))
+}
+
+// Grades a queued answer and rescores the attempt.
+func ReviewPostHandler(c *gin.Context) {
+	var form ManualGradeForm
+	data, ok := c.Get("sessionData")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+	sessionData, _ := data.(*middleware.SessionData)
+
+	attemptId, err := strconv.ParseInt(c.Param("attempt"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	questionId, err := strconv.ParseInt(c.Param("question"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindJSON(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
+
+		partTime, err := repository.QuizRepositoryInstance.GetParticipationTime(ctx, int32(attemptId))
+		if err != nil {
+			return err
+		}
+		if partTime.FinishedAt == nil {
+			return fmt.Errorf("attempt is not finished")
+		}
+		quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, partTime.QuizId)
+		if err != nil {
+			return err
+		}
+		if !canEditQuiz(sessionData, quizModel) {
+			return &apperrors.ErrPermissionDenied{Message: "Not enough permissions"}
+		}
+
+		questionModels, err := attemptQuestions(ctx, partTime)
+		if err != nil {
+			return err
+		}
+		var question *models.Question
+		for _, q := range questionModels {
+			if q.Id == int32(questionId) && q.ScoringMode == SCORING_MANUAL {
+				question = q
+			}
+		}
+		if question == nil {
+			return fmt.Errorf("invalid question")
+		}
+		if form.Points > question.Points {
+			return fmt.Errorf("invalid points")
+		}
+
+		now := time.Now().UTC()
+		err = repository.QuizRepositoryInstance.
+			UpdateManualGrade(ctx, &models.ManualGrade{
+				AttemptId:  partTime.Id,
+				QuestionId: question.Id,
+				Points:     &form.Points,
+				Feedback:   &form.Feedback,
+				ReviewerId: &sessionData.UserId,
+				GradedAt:   &now,
+			})
+		if err != nil {
+			return err
+		}
+		_, err = scoreAttempt(ctx, quizModel, partTime, now)
+		if err != nil {
+			return err
+		}
+		return issueCertificate(ctx, partTime.Id, now)
+	})
+	if _, ok := err.(*apperrors.ErrPermissionDenied); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "graded"})
+}
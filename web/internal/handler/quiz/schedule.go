@@ -0,0 +1,102 @@
+package quiz
+
+import (
+	"context"
+	"time"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/misc/apperrors"
+	"quiz_platform/internal/misc/logger"
+	"quiz_platform/internal/models"
+)
+
+// States of a quiz. Drafts and archived quizzes are hidden from everyone
+// but their editors, attempts are accepted only by published quizzes.
+const (
+	QUIZ_STATUS_DRAFT     = "draft"
+	QUIZ_STATUS_PUBLISHED = "published"
+	QUIZ_STATUS_CLOSED    = "closed"
+	QUIZ_STATUS_ARCHIVED  = "archived"
+)
+
+const DEFAULT_SCHEDULE_INTERVAL = time.Minute
+
+// Returns ErrPermissionDenied unless the quiz accepts attempts at the given
+// time. The grace extends the closing time like it extends time limits.
+func checkQuizWindow(quizModel *models.Quiz, now time.Time, grace time.Duration) error {
+	if quizModel.Status != QUIZ_STATUS_PUBLISHED {
+		return &apperrors.ErrPermissionDenied{Message: "quiz is not open"}
+	}
+	if quizModel.OpensAt != nil && now.Before(*quizModel.OpensAt) {
+		return &apperrors.ErrPermissionDenied{Message: "quiz is not open yet"}
+	}
+	if quizModel.ClosesAt != nil && now.After(quizModel.ClosesAt.Add(grace)) {
+		return &apperrors.ErrPermissionDenied{Message: "quiz is closed"}
+	}
+	return nil
+}
+
+func isQuizOpen(quizModel *models.Quiz, now time.Time) bool {
+	return checkQuizWindow(quizModel, now, 0) == nil
+}
+
+// Periodically publishes and closes quizzes by their opening and closing
+// times.
+func StartQuizScheduler(interval time.Duration) {
+	if interval <= 0 {
+		interval = DEFAULT_SCHEDULE_INTERVAL
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := RunQuizSchedule(context.Background()); err != nil {
+				logger.GlobalLogger.Errorf("cannot update quiz statuses: %v", err)
+			}
+		}
+	}()
+}
+
+// Updates the statuses of the scheduled quizzes and finalizes the attempts
+// left open in closed quizzes at the closing time.
+func RunQuizSchedule(ctx context.Context) error {
+	now := time.Now().UTC()
+	err := repository.QuizRepositoryInstance.UpdateScheduledQuizStatuses(ctx, attemptGrace(), now)
+	if err != nil {
+		return err
+	}
+
+	partTimes, err := repository.QuizRepositoryInstance.GetClosedQuizAttempts(ctx, attemptGrace(), now)
+	if err != nil {
+		return err
+	}
+	for _, partTime := range partTimes {
+		err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
+			return closeQuizAttempt(ctx, partTime)
+		})
+		if err != nil {
+			logger.GlobalLogger.Errorf("cannot finalize attempt %d: %v", partTime.Id, err)
+		}
+	}
+	return nil
+}
+
+// Closes the attempt at the closing time of the quiz or at its own
+// deadline when that comes first.
+func closeQuizAttempt(ctx context.Context, partTime *models.QuizParticipationTime) error {
+	quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, partTime.QuizId)
+	if err != nil {
+		return err
+	}
+	if quizModel.ClosesAt == nil {
+		return nil
+	}
+	finishedAt := *quizModel.ClosesAt
+	if deadline := attemptDeadline(quizModel, partTime); deadline != nil && deadline.Before(finishedAt) {
+		finishedAt = *deadline
+	}
+	if finishedAt.Before(partTime.StartedAt) {
+		finishedAt = partTime.StartedAt
+	}
+	return finishAttempt(ctx, quizModel, partTime, nil, finishedAt)
+}
@@ -0,0 +1,105 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/models"
+)
+
+// Question order is keyed by this value, choice orders by the question id.
+const QUESTION_ORDER_KEY = 0
+
+// Picks the seeds of a new attempt, nil when the quiz keeps the order.
+func newShuffleSeeds(quizModel *models.Quiz) (questionSeed *int64, choiceSeed *int64) {
+	if quizModel.ShuffleQuestions {
+		seed := rand.Int63()
+		questionSeed = &seed
+	}
+	if quizModel.ShuffleChoices {
+		seed := rand.Int63()
+		choiceSeed = &seed
+	}
+	return questionSeed, choiceSeed
+}
+
+// Shuffles n elements like rand.Shuffle, the same seed and key always give
+// the same permutation. Nil seed keeps the order.
+func shuffleWithSeed(seed *int64, key int32, n int, swap func(a, b int)) {
+	if seed == nil {
+		return
+	}
+	rand.New(rand.NewSource(*seed+int64(key))).Shuffle(n, swap)
+}
+
+// Puts the questions in the order the attempt shows them.
+func shuffleAttemptQuestions(partTime *models.QuizParticipationTime, questions []*models.Question) {
+	shuffleWithSeed(partTime.QuestionSeed, QUESTION_ORDER_KEY, len(questions), func(a, b int) {
+		questions[a], questions[b] = questions[b], questions[a]
+	})
+}
+
+// Puts the choices of the question in the order the attempt shows them.
+func shuffleAttemptChoices(partTime *models.QuizParticipationTime, questionId int32, n int, swap func(a, b int)) {
+	shuffleWithSeed(partTime.ChoiceSeed, questionId, n, swap)
+}
+
+// Returns the tokens standing in for the items of the question in the
+// attempt, keyed by item id. Unlike the item ids, the tokens are drawn at
+// random the first time the attempt needs them and tell nothing about
+// the right answer.
+func attemptItemTokens(ctx context.Context, attemptId int32, questionId int32, itemIds []int32) (map[int32]int32, error) {
+	tokens, err := repository.QuizRepositoryInstance.GetAttemptItemTokens(ctx, attemptId, questionId)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		order := rand.Perm(len(itemIds))
+		newTokens := make([]*models.AttemptItemToken, len(itemIds))
+		for j, itemId := range itemIds {
+			newTokens[j] = &models.AttemptItemToken{
+				AttemptId:  attemptId,
+				QuestionId: questionId,
+				ItemId:     itemId,
+				Token:      int32(order[j] + 1),
+			}
+		}
+		err = repository.QuizRepositoryInstance.AddAttemptItemTokens(ctx, newTokens)
+		if err != nil {
+			return nil, err
+		}
+		// Reloaded in case another request stored its tokens first.
+		tokens, err = repository.QuizRepositoryInstance.GetAttemptItemTokens(ctx, attemptId, questionId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	byItem := make(map[int32]int32, len(tokens))
+	for _, t := range tokens {
+		byItem[t.ItemId] = t.Token
+	}
+	return byItem, nil
+}
+
+// Maps the tokens sent by the participant back to the item ids.
+// Unknown and repeated tokens are rejected.
+func resolveItemTokens(tokens map[int32]int32, sent []int32) ([]int32, error) {
+	byToken := make(map[int32]int32, len(tokens))
+	for itemId, token := range tokens {
+		byToken[token] = itemId
+	}
+	itemIds := make([]int32, len(sent))
+	seen := make(map[int32]bool, len(sent))
+	for j, token := range sent {
+		itemId, ok := byToken[token]
+		if !ok || seen[token] {
+			return nil, fmt.Errorf("invalid item")
+		}
+		seen[token] = true
+		itemIds[j] = itemId
+	}
+	return itemIds, nil
+}
@@ -0,0 +1,59 @@
+package quiz
+
+import (
+	"context"
+	"time"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/misc/logger"
+	"quiz_platform/internal/models"
+)
+
+const DEFAULT_SWEEP_INTERVAL = time.Minute
+
+// Periodically finalizes attempts abandoned after their time limit.
+func StartAttemptSweeper(interval time.Duration) {
+	if interval <= 0 {
+		interval = DEFAULT_SWEEP_INTERVAL
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := SweepExpiredAttempts(context.Background()); err != nil {
+				logger.GlobalLogger.Errorf("cannot sweep expired attempts: %v", err)
+			}
+		}
+	}()
+}
+
+// Closes every expired attempt at its deadline with the answers saved so far.
+func SweepExpiredAttempts(ctx context.Context) error {
+	partTimes, err := repository.QuizRepositoryInstance.
+		GetExpiredParticipationTimes(ctx, attemptGrace(), time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	for _, partTime := range partTimes {
+		err = repository.TransactionManager.Run(ctx, func(ctx context.Context) error {
+			return closeExpiredAttempt(ctx, partTime)
+		})
+		if err != nil {
+			logger.GlobalLogger.Errorf("cannot finalize attempt %d: %v", partTime.Id, err)
+		}
+	}
+	return nil
+}
+
+func closeExpiredAttempt(ctx context.Context, partTime *models.QuizParticipationTime) error {
+	quizModel, err := repository.QuizRepositoryInstance.GetQuiz(ctx, partTime.QuizId)
+	if err != nil {
+		return err
+	}
+	deadline := attemptDeadline(quizModel, partTime)
+	if deadline == nil {
+		return nil
+	}
+	return finishAttempt(ctx, quizModel, partTime, nil, *deadline)
+}
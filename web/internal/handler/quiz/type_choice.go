@@ -0,0 +1,341 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/models"
+)
+
+// Question with a single correct choice.
+type choiceType struct{}
+
+func (t *choiceType) Name() string {
+	return "choice"
+}
+
+func (t *choiceType) Validate(q *Question) error {
+	q.ScoringMode = ""
+	correctCount, err := countCorrectChoices(q.Choices)
+	if err != nil {
+		return err
+	}
+	if correctCount != 1 {
+		return fmt.Errorf("invalid correct choice count")
+	}
+	return nil
+}
+
+func (t *choiceType) Save(ctx context.Context, q *Question) error {
+	for _, c := range q.Choices {
+		choiceId, err := repository.QuizRepositoryInstance.
+			AddChoice(ctx, q.Id, c.Text, c.IsCorrect)
+		if err != nil {
+			return err
+		}
+		if feedback := strings.TrimSpace(c.Feedback); feedback != "" {
+			err = repository.QuizRepositoryInstance.AddChoiceFeedback(ctx, choiceId, feedback)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *choiceType) Load(ctx context.Context, q *models.Question, dst *Question) error {
+	choices, err := loadChoices(ctx, q.Id, true)
+	if err != nil {
+		return err
+	}
+	dst.Choices = choices
+	return nil
+}
+
+func (t *choiceType) CorrectKey(ctx context.Context, q *models.Question, key *QuestionKeyRequest) error {
+	choices, err := correctedChoices(ctx, q.Id, key.CorrectChoices)
+	if err != nil {
+		return err
+	}
+	if err = t.Validate(&Question{Choices: choices}); err != nil {
+		return err
+	}
+	return updateChoiceKeys(ctx, choices)
+}
+
+func (t *choiceType) Render(ctx context.Context, attemptId int32, q *models.Question, dst *Question) error {
+	choices, err := loadChoices(ctx, q.Id, false)
+	if err != nil {
+		return err
+	}
+	dst.Choices = choices
+	return nil
+}
+
+func (t *choiceType) Grade(ctx context.Context, attemptId int32, q *models.Question, answer Answer) (float32, error) {
+	choiceId, err := strconv.ParseInt(answer.Value(), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	choices, err := repository.QuizRepositoryInstance.GetChoices(ctx, q.Id)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = questionChoiceIds(choices, []int32{int32(choiceId)}); err != nil {
+		return 0, err
+	}
+	correctChoice, err := repository.QuizRepositoryInstance.GetCorrectChoice(ctx, q.Id)
+	if err != nil {
+		return 0, err
+	}
+	err = repository.QuizRepositoryInstance.RemoveUserChoiceAnswers(ctx, q.Id, attemptId)
+	if err != nil {
+		return 0, err
+	}
+	err = repository.QuizRepositoryInstance.AddUserChoiceAnswer(ctx, attemptId, q.Id, int32(choiceId))
+	if err != nil {
+		return 0, err
+	}
+
+	if correctChoice.Id == int32(choiceId) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (t *choiceType) DescribeResult(ctx context.Context, attemptId int32, q *models.Question, dst *AnsweredQuestion) (float32, bool, error) {
+	correctChoice, err := repository.QuizRepositoryInstance.GetCorrectChoice(ctx, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	userChoices, err := repository.QuizRepositoryInstance.GetUserChoiceAnswers(ctx, attemptId, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	dst.RightAnswer = correctChoice.ChoiceText
+	if len(userChoices) == 0 {
+		return 0, false, nil
+	}
+
+	choice, err := repository.QuizRepositoryInstance.GetChoice(ctx, *userChoices[0].ChoiceId)
+	if err != nil {
+		return 0, false, err
+	}
+	feedback, err := choiceFeedback(ctx, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	dst.UserAnswer = choice.ChoiceText
+	dst.AnswerFeedback = feedback[choice.Id]
+	if correctChoice.Id == choice.Id {
+		return 1, true, nil
+	}
+	return 0, true, nil
+}
+
+// Also serves questions with several correct choices.
+func (t *choiceType) LoadAnswer(ctx context.Context, attemptId int32, q *models.Question) (Answer, error) {
+	userChoices, err := repository.QuizRepositoryInstance.GetUserChoiceAnswers(ctx, attemptId, q.Id)
+	if err != nil || len(userChoices) == 0 {
+		return nil, err
+	}
+	answer := make(Answer, len(userChoices))
+	for j, userChoice := range userChoices {
+		answer[j] = strconv.Itoa(int(*userChoice.ChoiceId))
+	}
+	return answer, nil
+}
+
+// Question with several correct choices.
+type multiType struct {
+	choiceType
+}
+
+func (t *multiType) Name() string {
+	return "multi"
+}
+
+func (t *multiType) Validate(q *Question) error {
+	if q.ScoringMode != SCORING_PARTIAL {
+		q.ScoringMode = SCORING_ALL_OR_NOTHING
+	}
+	correctCount, err := countCorrectChoices(q.Choices)
+	if err != nil {
+		return err
+	}
+	if correctCount == 0 {
+		return fmt.Errorf("invalid correct choice count")
+	}
+	return nil
+}
+
+func (t *multiType) CorrectKey(ctx context.Context, q *models.Question, key *QuestionKeyRequest) error {
+	choices, err := correctedChoices(ctx, q.Id, key.CorrectChoices)
+	if err != nil {
+		return err
+	}
+	if err = t.Validate(&Question{Choices: choices}); err != nil {
+		return err
+	}
+	return updateChoiceKeys(ctx, choices)
+}
+
+func (t *multiType) Grade(ctx context.Context, attemptId int32, q *models.Question, answer Answer) (float32, error) {
+	choiceIds, err := answer.Ids()
+	if err != nil {
+		return 0, err
+	}
+	choices, err := repository.QuizRepositoryInstance.GetChoices(ctx, q.Id)
+	if err != nil {
+		return 0, err
+	}
+	choiceIds, err = questionChoiceIds(choices, choiceIds)
+	if err != nil {
+		return 0, err
+	}
+	err = repository.QuizRepositoryInstance.RemoveUserChoiceAnswers(ctx, q.Id, attemptId)
+	if err != nil {
+		return 0, err
+	}
+	for _, choiceId := range choiceIds {
+		err = repository.QuizRepositoryInstance.AddUserChoiceAnswer(ctx, attemptId, q.Id, choiceId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return gradeMultiChoice(choices, choiceIds, q.ScoringMode), nil
+}
+
+func (t *multiType) DescribeResult(ctx context.Context, attemptId int32, q *models.Question, dst *AnsweredQuestion) (float32, bool, error) {
+	choices, err := repository.QuizRepositoryInstance.GetChoices(ctx, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	userChoices, err := repository.QuizRepositoryInstance.GetUserChoiceAnswers(ctx, attemptId, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	feedback, err := choiceFeedback(ctx, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	selected := make(map[int32]bool)
+	selectedIds := make([]int32, 0, len(userChoices))
+	for _, userChoice := range userChoices {
+		selected[*userChoice.ChoiceId] = true
+		selectedIds = append(selectedIds, *userChoice.ChoiceId)
+	}
+
+	rightTexts := make([]string, 0)
+	userTexts := make([]string, 0)
+	dst.Selections = make([]SelectedChoice, len(choices))
+	for j, choice := range choices {
+		dst.Selections[j] = SelectedChoice{
+			Text:       choice.ChoiceText,
+			IsSelected: selected[choice.Id],
+			IsCorrect:  choice.IsCorrect,
+			Feedback:   feedback[choice.Id],
+		}
+		if choice.IsCorrect {
+			rightTexts = append(rightTexts, choice.ChoiceText)
+		}
+		if selected[choice.Id] {
+			userTexts = append(userTexts, choice.ChoiceText)
+		}
+	}
+	dst.RightAnswer = strings.Join(rightTexts, ", ")
+	dst.UserAnswer = strings.Join(userTexts, ", ")
+
+	return gradeMultiChoice(choices, selectedIds, q.ScoringMode), len(selectedIds) > 0, nil
+}
+
+// Checks that the submitted ids belong to the choices of the question
+// and drops the repeated ones.
+func questionChoiceIds(choices []*models.Choice, ids []int32) ([]int32, error) {
+	known := make(map[int32]bool, len(choices))
+	for _, c := range choices {
+		known[c.Id] = true
+	}
+	seen := make(map[int32]bool, len(ids))
+	unique := make([]int32, 0, len(ids))
+	for _, id := range ids {
+		if !known[id] {
+			return nil, fmt.Errorf("invalid choice")
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
+
+func countCorrectChoices(choices []Choice) (int, error) {
+	if len(choices) == 0 {
+		return 0, fmt.Errorf("invalid choice count")
+	}
+	correctCount := 0
+	for _, c := range choices {
+		if c.IsCorrect {
+			correctCount++
+		}
+	}
+	return correctCount, nil
+}
+
+// Returns the choices of the question with the given numbers, counted
+// from 1, marked correct and the others not.
+func correctedChoices(ctx context.Context, questionId int32, correct []int) ([]Choice, error) {
+	choices, err := loadChoices(ctx, questionId, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range correct {
+		if n < 1 || n > len(choices) {
+			return nil, fmt.Errorf("invalid choice")
+		}
+		choices[n-1].IsCorrect = true
+	}
+	return choices, nil
+}
+
+func updateChoiceKeys(ctx context.Context, choices []Choice) error {
+	for _, c := range choices {
+		err := repository.QuizRepositoryInstance.UpdateChoiceCorrect(ctx, c.Id, c.IsCorrect)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Loads the choices of the question, the right answers and
+// the feedback are only filled in when requested.
+func loadChoices(ctx context.Context, questionId int32, withAnswers bool) ([]Choice, error) {
+	choiceModels, err := repository.QuizRepositoryInstance.GetChoices(ctx, questionId)
+	if err != nil {
+		return nil, err
+	}
+	feedback := make(map[int32]string)
+	if withAnswers {
+		feedback, err = choiceFeedback(ctx, questionId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	choices := make([]Choice, len(choiceModels))
+	for j, choice := range choiceModels {
+		choices[j] = Choice{
+			Id:         choice.Id,
+			QuestionId: questionId,
+			Text:       choice.ChoiceText,
+			IsCorrect:  withAnswers && choice.IsCorrect,
+			Feedback:   feedback[choice.Id],
+		}
+	}
+	return choices, nil
+}
@@ -0,0 +1,227 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/models"
+)
+
+// Question with blanks like {{1}} in its text, every blank has either
+// its own accepted answers or an inline list of options.
+type clozeType struct{}
+
+func (t *clozeType) Name() string {
+	return "cloze"
+}
+
+// Every placeholder in the text has to be described exactly once.
+func (t *clozeType) Validate(q *Question) error {
+	q.ScoringMode = ""
+	q.NegativePoints = 0
+
+	placeholders := clozeBlanks(q.Text)
+	if len(placeholders) == 0 || len(placeholders) != len(q.Blanks) {
+		return fmt.Errorf("invalid blank count")
+	}
+	described := make(map[int32]bool, len(q.Blanks))
+	for _, blank := range q.Blanks {
+		described[blank.Number] = true
+	}
+	seen := make(map[int32]bool, len(placeholders))
+	for _, number := range placeholders {
+		if seen[number] || !described[number] {
+			return fmt.Errorf("invalid blank %d", number)
+		}
+		seen[number] = true
+	}
+
+	for _, blank := range q.Blanks {
+		if len(blank.Options) == 0 {
+			if err := validateTextAnswerRules(blank.RightAnswers); err != nil {
+				return err
+			}
+			continue
+		}
+		correctCount := 0
+		for _, o := range blank.Options {
+			if o.IsCorrect {
+				correctCount++
+			}
+		}
+		if correctCount == 0 {
+			return fmt.Errorf("invalid correct option count")
+		}
+	}
+	return nil
+}
+
+func (t *clozeType) Save(ctx context.Context, q *Question) error {
+	for _, blank := range q.Blanks {
+		if len(blank.Options) == 0 {
+			if err := addTextAnswerRules(ctx, q.Id, blank.Number, blank.RightAnswers); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, o := range blank.Options {
+			_, err := repository.QuizRepositoryInstance.
+				AddClozeBlankOption(ctx, &models.ClozeBlankOption{
+					QuestionId:  q.Id,
+					BlankNumber: blank.Number,
+					OptionText:  o.Text,
+					IsCorrect:   o.IsCorrect,
+				})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *clozeType) Load(ctx context.Context, q *models.Question, dst *Question) error {
+	keys, err := repository.QuizRepositoryInstance.GetTextQuestionAnswers(ctx, q.Id)
+	if err != nil {
+		return err
+	}
+	options, err := repository.QuizRepositoryInstance.GetClozeBlankOptions(ctx, q.Id)
+	if err != nil {
+		return err
+	}
+	blankKeys := groupKeysByBlank(keys)
+	blankOptions := groupOptionsByBlank(options)
+
+	for _, number := range clozeBlanks(q.QuestionText) {
+		blank := ClozeBlank{
+			Number:       number,
+			RightAnswers: textAnswerRules(blankKeys[number]),
+		}
+		for _, option := range blankOptions[number] {
+			blank.Options = append(blank.Options, Choice{
+				Id:         option.Id,
+				QuestionId: q.Id,
+				Text:       option.OptionText,
+				IsCorrect:  option.IsCorrect,
+			})
+		}
+		dst.Blanks = append(dst.Blanks, blank)
+	}
+	return nil
+}
+
+func (t *clozeType) Render(ctx context.Context, attemptId int32, q *models.Question, dst *Question) error {
+	options, err := repository.QuizRepositoryInstance.GetClozeBlankOptions(ctx, q.Id)
+	if err != nil {
+		return err
+	}
+	blankOptions := groupOptionsByBlank(options)
+	for _, segment := range splitCloze(q.QuestionText) {
+		field := BlankField{Text: segment.Text, Blank: segment.Blank}
+		for _, option := range blankOptions[segment.Blank] {
+			field.Options = append(field.Options, option.OptionText)
+		}
+		dst.Fields = append(dst.Fields, field)
+	}
+	return nil
+}
+
+// Blank answers are sent in the order the blanks appear in the text.
+func (t *clozeType) Grade(ctx context.Context, attemptId int32, q *models.Question, answer Answer) (float32, error) {
+	keys, err := repository.QuizRepositoryInstance.GetTextQuestionAnswers(ctx, q.Id)
+	if err != nil {
+		return 0, err
+	}
+	options, err := repository.QuizRepositoryInstance.GetClozeBlankOptions(ctx, q.Id)
+	if err != nil {
+		return 0, err
+	}
+	blanks := clozeBlanks(q.QuestionText)
+	blankAnswers := make(map[int32]string, len(blanks))
+	for j, number := range blanks {
+		if j < len(answer) {
+			blankAnswers[number] = answer[j]
+		}
+	}
+
+	err = repository.QuizRepositoryInstance.RemoveUserTextAnswers(ctx, q.Id, attemptId)
+	if err != nil {
+		return 0, err
+	}
+	for number, text := range blankAnswers {
+		err = repository.QuizRepositoryInstance.
+			AddUserBlankAnswer(ctx, attemptId, q.Id, number, text)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return gradeCloze(blanks, keys, options, blankAnswers), nil
+}
+
+func (t *clozeType) DescribeResult(ctx context.Context, attemptId int32, q *models.Question, dst *AnsweredQuestion) (float32, bool, error) {
+	keys, err := repository.QuizRepositoryInstance.GetTextQuestionAnswers(ctx, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	options, err := repository.QuizRepositoryInstance.GetClozeBlankOptions(ctx, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	userBlanks, err := repository.QuizRepositoryInstance.GetUserBlankAnswers(ctx, attemptId, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	blankAnswers := make(map[int32]string, len(userBlanks))
+	for _, userBlank := range userBlanks {
+		if userBlank.TextAnswer != nil {
+			blankAnswers[userBlank.BlankNumber] = *userBlank.TextAnswer
+		}
+	}
+
+	blankKeys := groupKeysByBlank(keys)
+	blankOptions := groupOptionsByBlank(options)
+	var rightText, userText strings.Builder
+	for _, segment := range splitCloze(q.QuestionText) {
+		filled := FilledBlank{Text: segment.Text, Blank: segment.Blank}
+		if segment.Blank != 0 {
+			filled.Answer = blankAnswers[segment.Blank]
+			filled.RightAnswer = describeBlank(blankKeys[segment.Blank], blankOptions[segment.Blank])
+			filled.IsCorrect = gradeBlank(blankKeys[segment.Blank], blankOptions[segment.Blank], filled.Answer)
+			rightText.WriteString(filled.RightAnswer)
+			userText.WriteString(filled.Answer)
+		} else {
+			rightText.WriteString(segment.Text)
+			userText.WriteString(segment.Text)
+		}
+		dst.Segments = append(dst.Segments, filled)
+	}
+	dst.RightAnswer = rightText.String()
+	if len(blankAnswers) > 0 {
+		dst.UserAnswer = userText.String()
+	}
+
+	return gradeCloze(clozeBlanks(q.QuestionText), keys, options, blankAnswers), len(blankAnswers) > 0, nil
+}
+
+// Blank answers are returned in the order the blanks appear in the text.
+func (t *clozeType) LoadAnswer(ctx context.Context, attemptId int32, q *models.Question) (Answer, error) {
+	userBlanks, err := repository.QuizRepositoryInstance.GetUserBlankAnswers(ctx, attemptId, q.Id)
+	if err != nil || len(userBlanks) == 0 {
+		return nil, err
+	}
+	blankAnswers := make(map[int32]string, len(userBlanks))
+	for _, userBlank := range userBlanks {
+		if userBlank.TextAnswer != nil {
+			blankAnswers[userBlank.BlankNumber] = *userBlank.TextAnswer
+		}
+	}
+	blanks := clozeBlanks(q.QuestionText)
+	answer := make(Answer, len(blanks))
+	for j, number := range blanks {
+		answer[j] = blankAnswers[number]
+	}
+	return answer, nil
+}
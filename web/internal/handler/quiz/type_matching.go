@@ -0,0 +1,191 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/models"
+)
+
+// Question where the left items have to be paired with the right items.
+type matchingType struct{}
+
+func (t *matchingType) Name() string {
+	return "matching"
+}
+
+func (t *matchingType) Validate(q *Question) error {
+	q.ScoringMode = ""
+	q.NegativePoints = 0
+	if len(q.Pairs) < 2 {
+		return fmt.Errorf("invalid pair count")
+	}
+	return nil
+}
+
+func (t *matchingType) Save(ctx context.Context, q *Question) error {
+	for j, pair := range q.Pairs {
+		_, err := repository.QuizRepositoryInstance.
+			AddMatchingPair(ctx, &models.MatchingPair{
+				QuestionId: q.Id,
+				LeftText:   pair.Left,
+				RightText:  pair.Right,
+				Position:   int32(j + 1),
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *matchingType) Load(ctx context.Context, q *models.Question, dst *Question) error {
+	pairs, err := repository.QuizRepositoryInstance.GetMatchingPairs(ctx, q.Id)
+	if err != nil {
+		return err
+	}
+	dst.Pairs = make([]MatchingPair, len(pairs))
+	for j, pair := range pairs {
+		dst.Pairs[j] = MatchingPair{
+			Id:    pair.Id,
+			Left:  pair.LeftText,
+			Right: pair.RightText,
+		}
+	}
+	return nil
+}
+
+// The right items are shown as options identified by the tokens of the
+// attempt, in the order of the tokens.
+func (t *matchingType) Render(ctx context.Context, attemptId int32, q *models.Question, dst *Question) error {
+	pairs, err := repository.QuizRepositoryInstance.GetMatchingPairs(ctx, q.Id)
+	if err != nil {
+		return err
+	}
+	tokens, err := matchingPairTokens(ctx, attemptId, q.Id, pairs)
+	if err != nil {
+		return err
+	}
+	dst.Pairs = make([]MatchingPair, len(pairs))
+	dst.Options = make([]Choice, len(pairs))
+	for j, pair := range pairs {
+		dst.Pairs[j] = MatchingPair{Id: pair.Id, Left: pair.LeftText}
+		dst.Options[j] = Choice{Id: tokens[pair.Id], QuestionId: q.Id, Text: pair.RightText}
+	}
+	sort.Slice(dst.Options, func(a, b int) bool {
+		return dst.Options[a].Id < dst.Options[b].Id
+	})
+	return nil
+}
+
+// The left items are sent by pair id, the right items by token.
+func (t *matchingType) Grade(ctx context.Context, attemptId int32, q *models.Question, answer Answer) (float32, error) {
+	sent, err := answer.Matches()
+	if err != nil {
+		return 0, err
+	}
+	pairs, err := repository.QuizRepositoryInstance.GetMatchingPairs(ctx, q.Id)
+	if err != nil {
+		return 0, err
+	}
+	tokens, err := matchingPairTokens(ctx, attemptId, q.Id, pairs)
+	if err != nil {
+		return 0, err
+	}
+	matches := make(map[int32]int32, len(sent))
+	for pairId, token := range sent {
+		if _, ok := tokens[pairId]; !ok {
+			return 0, fmt.Errorf("invalid match")
+		}
+		matchedPairIds, err := resolveItemTokens(tokens, []int32{token})
+		if err != nil {
+			return 0, err
+		}
+		matches[pairId] = matchedPairIds[0]
+	}
+	err = repository.QuizRepositoryInstance.RemoveUserMatchingAnswers(ctx, q.Id, attemptId)
+	if err != nil {
+		return 0, err
+	}
+	for pairId, matchedPairId := range matches {
+		err = repository.QuizRepositoryInstance.
+			AddUserMatchingAnswer(ctx, attemptId, q.Id, pairId, matchedPairId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return gradeMatching(pairs, matches), nil
+}
+
+func (t *matchingType) DescribeResult(ctx context.Context, attemptId int32, q *models.Question, dst *AnsweredQuestion) (float32, bool, error) {
+	pairs, err := repository.QuizRepositoryInstance.GetMatchingPairs(ctx, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	userMatches, err := repository.QuizRepositoryInstance.GetUserMatchingAnswers(ctx, attemptId, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+
+	rightTexts := make(map[int32]string, len(pairs))
+	for _, pair := range pairs {
+		rightTexts[pair.Id] = pair.RightText
+	}
+	matches := make(map[int32]int32, len(userMatches))
+	for _, userMatch := range userMatches {
+		matches[userMatch.PairId] = userMatch.MatchedPairId
+	}
+
+	rightPairs := make([]string, len(pairs))
+	userPairs := make([]string, 0, len(userMatches))
+	dst.Matches = make([]MatchedPair, len(pairs))
+	for j, pair := range pairs {
+		dst.Matches[j] = MatchedPair{
+			Left:        pair.LeftText,
+			Right:       rightTexts[matches[pair.Id]],
+			RightAnswer: pair.RightText,
+			IsCorrect:   isPairMatched(pairs, pair, matches[pair.Id]),
+		}
+		rightPairs[j] = pair.LeftText + " → " + pair.RightText
+		if right, ok := rightTexts[matches[pair.Id]]; ok {
+			userPairs = append(userPairs, pair.LeftText+" → "+right)
+		}
+	}
+	dst.RightAnswer = strings.Join(rightPairs, ", ")
+	dst.UserAnswer = strings.Join(userPairs, ", ")
+
+	return gradeMatching(pairs, matches), len(userMatches) > 0, nil
+}
+
+func (t *matchingType) LoadAnswer(ctx context.Context, attemptId int32, q *models.Question) (Answer, error) {
+	userMatches, err := repository.QuizRepositoryInstance.GetUserMatchingAnswers(ctx, attemptId, q.Id)
+	if err != nil || len(userMatches) == 0 {
+		return nil, err
+	}
+	pairs, err := repository.QuizRepositoryInstance.GetMatchingPairs(ctx, q.Id)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := matchingPairTokens(ctx, attemptId, q.Id, pairs)
+	if err != nil {
+		return nil, err
+	}
+	answer := make(Answer, len(userMatches))
+	for j, userMatch := range userMatches {
+		answer[j] = fmt.Sprintf("%d:%d", userMatch.PairId, tokens[userMatch.MatchedPairId])
+	}
+	return answer, nil
+}
+
+// Returns the tokens of the attempt standing in for the right items, keyed by pair id.
+func matchingPairTokens(ctx context.Context, attemptId int32, questionId int32, pairs []*models.MatchingPair) (map[int32]int32, error) {
+	pairIds := make([]int32, len(pairs))
+	for j, pair := range pairs {
+		pairIds[j] = pair.Id
+	}
+	return attemptItemTokens(ctx, attemptId, questionId, pairIds)
+}
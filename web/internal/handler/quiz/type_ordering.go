@@ -0,0 +1,178 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/models"
+)
+
+// Question where the items have to be put in the right order.
+type orderingType struct{}
+
+func (t *orderingType) Name() string {
+	return "ordering"
+}
+
+func (t *orderingType) Validate(q *Question) error {
+	if q.ScoringMode != SCORING_POSITION && q.ScoringMode != SCORING_KENDALL {
+		q.ScoringMode = SCORING_EXACT
+	}
+	q.NegativePoints = 0
+	if len(q.Items) < 2 {
+		return fmt.Errorf("invalid item count")
+	}
+	return nil
+}
+
+func (t *orderingType) Save(ctx context.Context, q *Question) error {
+	for j, item := range q.Items {
+		_, err := repository.QuizRepositoryInstance.
+			AddOrderingItem(ctx, &models.OrderingItem{
+				QuestionId: q.Id,
+				ItemText:   item.Text,
+				Position:   int32(j + 1),
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *orderingType) Load(ctx context.Context, q *models.Question, dst *Question) error {
+	items, err := repository.QuizRepositoryInstance.GetOrderingItems(ctx, q.Id)
+	if err != nil {
+		return err
+	}
+	dst.Items = make([]Choice, len(items))
+	for j, item := range items {
+		dst.Items[j] = Choice{
+			Id:         item.Id,
+			QuestionId: q.Id,
+			Text:       item.ItemText,
+		}
+	}
+	return nil
+}
+
+// The items are identified by the tokens of the attempt and shown in
+// the order of the tokens.
+func (t *orderingType) Render(ctx context.Context, attemptId int32, q *models.Question, dst *Question) error {
+	if err := t.Load(ctx, q, dst); err != nil {
+		return err
+	}
+	itemIds := make([]int32, len(dst.Items))
+	for j, item := range dst.Items {
+		itemIds[j] = item.Id
+	}
+	tokens, err := attemptItemTokens(ctx, attemptId, q.Id, itemIds)
+	if err != nil {
+		return err
+	}
+	for j := range dst.Items {
+		dst.Items[j].Id = tokens[dst.Items[j].Id]
+	}
+	sort.Slice(dst.Items, func(a, b int) bool {
+		return dst.Items[a].Id < dst.Items[b].Id
+	})
+	return nil
+}
+
+func (t *orderingType) Grade(ctx context.Context, attemptId int32, q *models.Question, answer Answer) (float32, error) {
+	sent, err := answer.Ids()
+	if err != nil {
+		return 0, err
+	}
+	items, err := repository.QuizRepositoryInstance.GetOrderingItems(ctx, q.Id)
+	if err != nil {
+		return 0, err
+	}
+	tokens, err := orderingItemTokens(ctx, attemptId, q.Id, items)
+	if err != nil {
+		return 0, err
+	}
+	itemIds, err := resolveItemTokens(tokens, sent)
+	if err != nil {
+		return 0, err
+	}
+	err = repository.QuizRepositoryInstance.RemoveUserOrderingAnswers(ctx, q.Id, attemptId)
+	if err != nil {
+		return 0, err
+	}
+	for j, itemId := range itemIds {
+		err = repository.QuizRepositoryInstance.
+			AddUserOrderingAnswer(ctx, attemptId, q.Id, itemId, int32(j+1))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return gradeOrdering(items, itemIds, q.ScoringMode), nil
+}
+
+func (t *orderingType) DescribeResult(ctx context.Context, attemptId int32, q *models.Question, dst *AnsweredQuestion) (float32, bool, error) {
+	items, err := repository.QuizRepositoryInstance.GetOrderingItems(ctx, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	userItems, err := repository.QuizRepositoryInstance.GetUserOrderingAnswers(ctx, attemptId, q.Id)
+	if err != nil {
+		return 0, false, err
+	}
+
+	itemTexts := make(map[int32]string, len(items))
+	rightTexts := make([]string, len(items))
+	for j, item := range items {
+		itemTexts[item.Id] = item.ItemText
+		rightTexts[j] = item.ItemText
+	}
+	itemIds := make([]int32, len(userItems))
+	userTexts := make([]string, len(userItems))
+	dst.Placements = make([]PlacedItem, len(userItems))
+	for j, userItem := range userItems {
+		itemIds[j] = userItem.ItemId
+		userTexts[j] = itemTexts[userItem.ItemId]
+		dst.Placements[j] = PlacedItem{
+			Text:      itemTexts[userItem.ItemId],
+			IsCorrect: j < len(items) && items[j].Id == userItem.ItemId,
+		}
+	}
+	dst.RightAnswer = strings.Join(rightTexts, " → ")
+	dst.UserAnswer = strings.Join(userTexts, " → ")
+
+	return gradeOrdering(items, itemIds, q.ScoringMode), len(userItems) > 0, nil
+}
+
+func (t *orderingType) LoadAnswer(ctx context.Context, attemptId int32, q *models.Question) (Answer, error) {
+	userItems, err := repository.QuizRepositoryInstance.GetUserOrderingAnswers(ctx, attemptId, q.Id)
+	if err != nil || len(userItems) == 0 {
+		return nil, err
+	}
+	items, err := repository.QuizRepositoryInstance.GetOrderingItems(ctx, q.Id)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := orderingItemTokens(ctx, attemptId, q.Id, items)
+	if err != nil {
+		return nil, err
+	}
+	answer := make(Answer, len(userItems))
+	for j, userItem := range userItems {
+		answer[j] = strconv.Itoa(int(tokens[userItem.ItemId]))
+	}
+	return answer, nil
+}
+
+// Returns the tokens of the attempt standing in for the items, keyed by item id.
+func orderingItemTokens(ctx context.Context, attemptId int32, questionId int32, items []*models.OrderingItem) (map[int32]int32, error) {
+	itemIds := make([]int32, len(items))
+	for j, item := range items {
+		itemIds[j] = item.Id
+	}
+	return attemptItemTokens(ctx, attemptId, questionId, itemIds)
+}
@@ -0,0 +1,336 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/misc/apperrors"
+	"quiz_platform/internal/models"
+)
+
+// Question with a typed answer checked against the accepted answers.
+type textType struct{}
+
+func (t *textType) Name() string {
+	return "text"
+}
+
+func (t *textType) Validate(q *Question) error {
+	q.NegativePoints = 0
+	// The answer of a parameterized question comes from its formula.
+	if q.Formula != "" {
+		q.ScoringMode = ""
+		q.RightAnswers = nil
+		return nil
+	}
+	if q.ScoringMode != SCORING_MANUAL {
+		q.ScoringMode = ""
+	}
+	if len(q.RightAnswers) == 0 && q.RightAnswer != "" {
+		q.RightAnswers = []TextAnswerRule{{Text: q.RightAnswer, MatchMode: MATCH_EXACT}}
+	}
+	// Accepted answers of a manually graded question only guide the reviewer.
+	if q.ScoringMode == SCORING_MANUAL && len(q.RightAnswers) == 0 {
+		return nil
+	}
+	return validateTextAnswerRules(q.RightAnswers)
+}
+
+func (t *textType) Save(ctx context.Context, q *Question) error {
+	return addTextAnswerRules(ctx, q.Id, 0, q.RightAnswers)
+}
+
+func (t *textType) Load(ctx context.Context, q *models.Question, dst *Question) error {
+	keys, err := repository.QuizRepositoryInstance.GetTextQuestionAnswers(ctx, q.Id)
+	if err != nil {
+		return err
+	}
+	dst.RightAnswers = textAnswerRules(keys)
+	return nil
+}
+
+func (t *textType) CorrectKey(ctx context.Context, q *models.Question, key *QuestionKeyRequest) error {
+	if q.AnswerFormula != "" {
+		return fmt.Errorf("answer of a parameterized question comes from its formula")
+	}
+	corrected := &Question{ScoringMode: q.ScoringMode, RightAnswers: key.RightAnswers}
+	if err := t.Validate(corrected); err != nil {
+		return err
+	}
+	err := repository.QuizRepositoryInstance.RemoveTextQuestionAnswers(ctx, q.Id)
+	if err != nil {
+		return err
+	}
+	return addTextAnswerRules(ctx, q.Id, 0, corrected.RightAnswers)
+}
+
+func (t *textType) Render(ctx context.Context, attemptId int32, q *models.Question, dst *Question) error {
+	return nil
+}
+
+func (t *textType) Grade(ctx context.Context, attemptId int32, q *models.Question, answer Answer) (float32, error) {
+	if err := storeTextAnswer(ctx, attemptId, q.Id, answer.Value()); err != nil {
+		return 0, err
+	}
+	if q.ScoringMode == SCORING_MANUAL {
+		credit, _, err := manualCredit(ctx, attemptId, q, &AnsweredQuestion{})
+		return credit, err
+	}
+
+	keys, err := textAnswerKeys(ctx, attemptId, q)
+	if err != nil {
+		return 0, err
+	}
+	if gradeText(keys, answer.Value()) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (t *textType) DescribeResult(ctx context.Context, attemptId int32, q *models.Question, dst *AnsweredQuestion) (float32, bool, error) {
+	keys, err := textAnswerKeys(ctx, attemptId, q)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(keys) > 0 {
+		dst.RightAnswer = describeText(keys)
+	}
+
+	userText, err := getUserTextAnswer(ctx, attemptId, q.Id)
+	if err != nil || userText == nil {
+		return 0, false, err
+	}
+	dst.UserAnswer = *userText
+	if q.ScoringMode == SCORING_MANUAL {
+		credit, _, err := manualCredit(ctx, attemptId, q, dst)
+		return credit, true, err
+	}
+	if gradeText(keys, *userText) {
+		return 1, true, nil
+	}
+	return 0, true, nil
+}
+
+func (t *textType) LoadAnswer(ctx context.Context, attemptId int32, q *models.Question) (Answer, error) {
+	return loadTextAnswer(ctx, attemptId, q.Id)
+}
+
+// Question with a number answer, optionally followed by a unit.
+type numericType struct{}
+
+func (t *numericType) Name() string {
+	return "numeric"
+}
+
+func (t *numericType) Validate(q *Question) error {
+	q.ScoringMode = ""
+	q.NegativePoints = 0
+	if q.Numeric == nil && q.Formula != "" {
+		q.Numeric = &NumericAnswer{}
+	}
+	if q.Numeric == nil {
+		return fmt.Errorf("invalid numeric answer")
+	}
+	if q.Numeric.ToleranceMode == "" {
+		q.Numeric.ToleranceMode = TOLERANCE_ABSOLUTE
+	}
+	units := make([]string, 0, len(q.Numeric.Units))
+	for _, u := range q.Numeric.Units {
+		if u = strings.TrimSpace(u); u != "" {
+			units = append(units, u)
+		}
+	}
+	q.Numeric.Units = units
+	return nil
+}
+
+func (t *numericType) Save(ctx context.Context, q *Question) error {
+	return repository.QuizRepositoryInstance.
+		AddNumericQuestionAnswer(ctx, &models.NumericQuestionAnswer{
+			QuestionId:    q.Id,
+			TargetValue:   q.Numeric.Value,
+			Tolerance:     q.Numeric.Tolerance,
+			ToleranceMode: q.Numeric.ToleranceMode,
+			Units:         q.Numeric.Units,
+		})
+}
+
+func (t *numericType) Load(ctx context.Context, q *models.Question, dst *Question) error {
+	key, err := repository.QuizRepositoryInstance.GetNumericQuestionAnswer(ctx, q.Id)
+	if err != nil {
+		return err
+	}
+	dst.Numeric = &NumericAnswer{
+		Value:         key.TargetValue,
+		Tolerance:     key.Tolerance,
+		ToleranceMode: key.ToleranceMode,
+		Units:         key.Units,
+	}
+	return nil
+}
+
+// The target of a parameterized question still comes from its formula.
+func (t *numericType) CorrectKey(ctx context.Context, q *models.Question, key *QuestionKeyRequest) error {
+	corrected := &Question{Numeric: key.Numeric, Formula: q.AnswerFormula}
+	if err := t.Validate(corrected); err != nil {
+		return err
+	}
+	return repository.QuizRepositoryInstance.
+		UpdateNumericQuestionAnswer(ctx, &models.NumericQuestionAnswer{
+			QuestionId:    q.Id,
+			TargetValue:   corrected.Numeric.Value,
+			Tolerance:     corrected.Numeric.Tolerance,
+			ToleranceMode: corrected.Numeric.ToleranceMode,
+			Units:         corrected.Numeric.Units,
+		})
+}
+
+func (t *numericType) Render(ctx context.Context, attemptId int32, q *models.Question, dst *Question) error {
+	return nil
+}
+
+func (t *numericType) Grade(ctx context.Context, attemptId int32, q *models.Question, answer Answer) (float32, error) {
+	key, err := numericAnswerKey(ctx, attemptId, q)
+	if err != nil {
+		return 0, err
+	}
+	// Numeric answers are stored as typed to keep the units.
+	if err = storeTextAnswer(ctx, attemptId, q.Id, answer.Value()); err != nil {
+		return 0, err
+	}
+
+	if gradeNumeric(key, answer.Value()) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (t *numericType) DescribeResult(ctx context.Context, attemptId int32, q *models.Question, dst *AnsweredQuestion) (float32, bool, error) {
+	key, err := numericAnswerKey(ctx, attemptId, q)
+	if err != nil {
+		return 0, false, err
+	}
+	dst.RightAnswer = describeNumeric(key)
+
+	userText, err := getUserTextAnswer(ctx, attemptId, q.Id)
+	if err != nil || userText == nil {
+		return 0, false, err
+	}
+	dst.UserAnswer = *userText
+	if gradeNumeric(key, *userText) {
+		return 1, true, nil
+	}
+	return 0, true, nil
+}
+
+func (t *numericType) LoadAnswer(ctx context.Context, attemptId int32, q *models.Question) (Answer, error) {
+	return loadTextAnswer(ctx, attemptId, q.Id)
+}
+
+// Returns the accepted answers of the text question in the attempt. The answer
+// of a parameterized question is its formula result, compared as a number.
+func textAnswerKeys(ctx context.Context, attemptId int32, q *models.Question) ([]*models.TextQuestionAnswer, error) {
+	if q.AnswerFormula == "" {
+		return repository.QuizRepositoryInstance.GetTextQuestionAnswers(ctx, q.Id)
+	}
+	value, err := formulaAnswer(ctx, attemptId, q)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.TextQuestionAnswer{{
+		QuestionId:  q.Id,
+		RightAnswer: formatValue(value),
+		MatchMode:   MATCH_NUMERIC,
+	}}, nil
+}
+
+// Returns the right answer of the numeric question in the attempt, the
+// target of a parameterized question is its formula result.
+func numericAnswerKey(ctx context.Context, attemptId int32, q *models.Question) (*models.NumericQuestionAnswer, error) {
+	key, err := repository.QuizRepositoryInstance.GetNumericQuestionAnswer(ctx, q.Id)
+	if err != nil || q.AnswerFormula == "" {
+		return key, err
+	}
+	key.TargetValue, err = formulaAnswer(ctx, attemptId, q)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Checks the accepted answers of a text question or of a single cloze blank.
+func validateTextAnswerRules(rules []TextAnswerRule) error {
+	if len(rules) == 0 {
+		return fmt.Errorf("invalid right answer count")
+	}
+	for i, rule := range rules {
+		if rule.MatchMode == "" {
+			rules[i].MatchMode = MATCH_EXACT
+		}
+		if rule.MatchMode == MATCH_REGEX {
+			if _, err := compileAnswerRegexp(rule.Text); err != nil {
+				return fmt.Errorf("invalid answer pattern: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Inserts the accepted answers of a text question or of a single cloze blank.
+func addTextAnswerRules(ctx context.Context, questionId int32, blankNumber int32, rules []TextAnswerRule) error {
+	for _, rule := range rules {
+		_, err := repository.QuizRepositoryInstance.
+			AddTextQuestionAnswer(ctx, &models.TextQuestionAnswer{
+				QuestionId:  questionId,
+				BlankNumber: blankNumber,
+				RightAnswer: rule.Text,
+				MatchMode:   rule.MatchMode,
+				MaxDistance: rule.MaxDistance,
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func textAnswerRules(keys []*models.TextQuestionAnswer) []TextAnswerRule {
+	rules := make([]TextAnswerRule, len(keys))
+	for j, key := range keys {
+		rules[j] = TextAnswerRule{
+			Text:        key.RightAnswer,
+			MatchMode:   key.MatchMode,
+			MaxDistance: key.MaxDistance,
+		}
+	}
+	return rules
+}
+
+func storeTextAnswer(ctx context.Context, attemptId int32, questionId int32, text string) error {
+	err := repository.QuizRepositoryInstance.RemoveUserTextAnswers(ctx, questionId, attemptId)
+	if err != nil {
+		return err
+	}
+	return repository.QuizRepositoryInstance.AddUserTextAnswer(ctx, attemptId, questionId, text)
+}
+
+// Returns nil when the question was left unanswered.
+func getUserTextAnswer(ctx context.Context, attemptId int32, questionId int32) (*string, error) {
+	userText, err := repository.QuizRepositoryInstance.GetUserTextAnswer(ctx, attemptId, questionId)
+	if _, ok := err.(*apperrors.ErrNotFound); ok {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return userText.TextAnswer, nil
+}
+
+func loadTextAnswer(ctx context.Context, attemptId int32, questionId int32) (Answer, error) {
+	userText, err := getUserTextAnswer(ctx, attemptId, questionId)
+	if err != nil || userText == nil {
+		return nil, err
+	}
+	return Answer{*userText}, nil
+}
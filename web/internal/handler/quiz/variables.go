@@ -0,0 +1,237 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"strconv"
+
+	"quiz_platform/internal/handler/repository"
+	"quiz_platform/internal/models"
+	"quiz_platform/internal/utility"
+)
+
+// Placeholder of a variable in the text of a question, like {a}.
+var variablePlaceholderRegexp = regexp.MustCompile(`\{([A-Za-z_]\w*)\}`)
+
+var variableNameRegexp = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+
+// Variable of a parameterized question, every attempt gets a random value
+// from min to max in steps of step.
+type QuestionVariable struct {
+	Name string  `json:"name" binding:"required"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Step float64 `json:"step" binding:"min=0"`
+}
+
+// Limits on the values a parameterized question can take, so that the
+// formula can be checked with every combination of them.
+const (
+	MAX_VARIABLE_VALUES = 1000
+	MAX_FORMULA_CHECKS  = 100000
+)
+
+// Checks the formula and the variables of a parameterized question.
+// The formula has to give a value with every combination of the values
+// the variables can take.
+func validateVariables(q *Question) error {
+	if q.Formula == "" && len(q.Variables) == 0 {
+		return nil
+	}
+	if q.Type != "numeric" && q.Type != "text" {
+		return fmt.Errorf("question type %q has no variables", q.Type)
+	}
+	if q.Formula == "" {
+		return fmt.Errorf("invalid answer formula")
+	}
+
+	names := make(map[string]bool, len(q.Variables))
+	checks := 1
+	for i, v := range q.Variables {
+		if !variableNameRegexp.MatchString(v.Name) {
+			return fmt.Errorf("invalid variable name %q", v.Name)
+		}
+		if names[v.Name] {
+			return fmt.Errorf("duplicate variable %q", v.Name)
+		}
+		names[v.Name] = true
+		if v.Step == 0 {
+			q.Variables[i].Step = 1
+		}
+		if v.Min > v.Max {
+			return fmt.Errorf("invalid range of variable %q", v.Name)
+		}
+		steps, ok := variableSteps(v.Min, v.Max, q.Variables[i].Step)
+		if !ok {
+			return fmt.Errorf("variable %q takes more than %d values", v.Name, MAX_VARIABLE_VALUES)
+		}
+		checks *= steps
+		if checks > MAX_FORMULA_CHECKS {
+			return fmt.Errorf("variables take more than %d combinations of values", MAX_FORMULA_CHECKS)
+		}
+	}
+
+	values := make(map[string]float64, len(q.Variables))
+	return checkFormula(q.Formula, q.Variables, values)
+}
+
+// Evaluates the formula with every combination of the values
+// of the variables not yet set in values.
+func checkFormula(formula string, variables []QuestionVariable, values map[string]float64) error {
+	if len(variables) == 0 {
+		if _, err := utility.EvalFormula(formula, values); err != nil {
+			return fmt.Errorf("invalid answer formula: %v", err)
+		}
+		return nil
+	}
+	v := variables[0]
+	steps, _ := variableSteps(v.Min, v.Max, v.Step)
+	for j := 0; j < steps; j++ {
+		values[v.Name] = roundValue(v.Min + v.Step*float64(j))
+		if err := checkFormula(formula, variables[1:], values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the number of values a variable takes, false when there are
+// more than MAX_VARIABLE_VALUES of them.
+func variableSteps(min float64, max float64, step float64) (int, bool) {
+	steps := math.Floor((max-min)/step+1e-9) + 1
+	if !(steps >= 1 && steps <= MAX_VARIABLE_VALUES) {
+		return 1, false
+	}
+	return int(steps), true
+}
+
+func addQuestionVariables(ctx context.Context, q *Question) error {
+	for _, v := range q.Variables {
+		err := repository.QuizRepositoryInstance.
+			AddQuestionVariable(ctx, &models.QuestionVariable{
+				QuestionId: q.Id,
+				Name:       v.Name,
+				MinValue:   v.Min,
+				MaxValue:   v.Max,
+				Step:       v.Step,
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fills the variables of the questions for editing.
+func loadQuestionVariables(ctx context.Context, questions []Question) error {
+	questionIds := make([]int32, len(questions))
+	for i, q := range questions {
+		questionIds[i] = q.Id
+	}
+	variables, err := repository.QuizRepositoryInstance.GetQuestionVariables(ctx, questionIds)
+	if err != nil {
+		return err
+	}
+	for i := range questions {
+		for _, v := range variables {
+			if v.QuestionId == questions[i].Id {
+				questions[i].Variables = append(questions[i].Variables, QuestionVariable{
+					Name: v.Name,
+					Min:  v.MinValue,
+					Max:  v.MaxValue,
+					Step: v.Step,
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// Rounds away the floating point noise of the generated values and
+// formula results, so that 0.1 + 0.2 gives 0.3.
+func roundValue(value float64) float64 {
+	return math.Round(value*1e9) / 1e9
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(roundValue(value), 'f', -1, 64)
+}
+
+// Generates the values of the variables of the questions given to a new
+// attempt and stores them in the attempt.
+func generateAttemptVariables(ctx context.Context, attemptId int32, questionIds []int32) error {
+	variables, err := repository.QuizRepositoryInstance.GetQuestionVariables(ctx, questionIds)
+	if err != nil {
+		return err
+	}
+	values := make([]*models.AttemptVariable, len(variables))
+	for i, v := range variables {
+		// Variables stored before the range was limited take their minimum.
+		steps, _ := variableSteps(v.MinValue, v.MaxValue, v.Step)
+		values[i] = &models.AttemptVariable{
+			AttemptId:  attemptId,
+			QuestionId: v.QuestionId,
+			Name:       v.Name,
+			Value:      roundValue(v.MinValue + v.Step*float64(rand.Intn(steps))),
+		}
+	}
+	return repository.QuizRepositoryInstance.AddAttemptVariables(ctx, values)
+}
+
+// Returns the values of the question variables in the attempt. Variables
+// without a stored value, like in attempts started before the question got
+// them, take their minimum.
+func attemptValues(ctx context.Context, attemptId int32, q *models.Question) (map[string]float64, error) {
+	variables, err := repository.QuizRepositoryInstance.GetQuestionVariables(ctx, []int32{q.Id})
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64, len(variables))
+	for _, v := range variables {
+		values[v.Name] = v.MinValue
+	}
+
+	stored, err := repository.QuizRepositoryInstance.GetAttemptVariables(ctx, attemptId, q.Id)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range stored {
+		values[v.Name] = v.Value
+	}
+	return values, nil
+}
+
+// Computes the right answer of a parameterized question in the attempt.
+func formulaAnswer(ctx context.Context, attemptId int32, q *models.Question) (float64, error) {
+	values, err := attemptValues(ctx, attemptId, q)
+	if err != nil {
+		return 0, err
+	}
+	res, err := utility.EvalFormula(q.AnswerFormula, values)
+	if err != nil {
+		return 0, err
+	}
+	return roundValue(res), nil
+}
+
+// Returns the question text with the values of the attempt in place of
+// the variable placeholders. Unknown placeholders are kept as they are.
+func attemptQuestionText(ctx context.Context, attemptId int32, q *models.Question) (string, error) {
+	if q.AnswerFormula == "" {
+		return q.QuestionText, nil
+	}
+	values, err := attemptValues(ctx, attemptId, q)
+	if err != nil {
+		return "", err
+	}
+	return variablePlaceholderRegexp.ReplaceAllStringFunc(q.QuestionText, func(placeholder string) string {
+		value, ok := values[placeholder[1:len(placeholder)-1]]
+		if !ok {
+			return placeholder
+		}
+		return formatValue(value)
+	}), nil
+}
@@ -12,4 +12,8 @@ var (
 type ActionsRepository interface {
 	// May return ErrInternal or ErrNotFound on failure.
 	GetAllActions(ctx context.Context) ([]*models.UserAction, error)
+
+	// Nil user id records an action made outside of the site.
+	// May return ErrInternal or ErrNotFound on failure.
+	AddAction(ctx context.Context, userId *int32, description string) error
 }
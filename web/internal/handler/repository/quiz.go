@@ -18,11 +18,24 @@ type QuizRepository interface {
 	GetCategoriesPairs(ctx context.Context, quizIds []int32) ([]int32, []int32, error)
 
 	// May return ErrInternal or ErrNotFound on failure.
-	GetAllQuizzes(ctx context.Context, categoryId int32) ([]*models.Quiz, error)
+	GetAllQuizzes(ctx context.Context, categoryId int32, viewerId int32, showHidden bool) ([]*models.Quiz, error)
 
 	// May return ErrInternal or ErrInvalidInput on failure.
 	AddQuiz(ctx context.Context, title string, desc string, author_id int32) (int32, error)
 
+	// May return ErrInternal or ErrNotFound on failure.
+	EditQuiz(ctx context.Context, id int32, title string, desc string) (int32, error)
+
+	// Updates the settings of the quiz which are not versioned.
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateQuizSettings(ctx context.Context, quiz *models.Quiz) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddQuizVersion(ctx context.Context, quizId int32, version int32, title string, desc string) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetQuizVersion(ctx context.Context, quizId int32, version int32) (*models.QuizVersion, error)
+
 	// May return ErrInternal or ErrNotFound on failure.
 	RemoveQuizCategories(ctx context.Context, quizId int32) error
 
@@ -30,14 +43,36 @@ type QuizRepository interface {
 	AddQuizCategories(ctx context.Context, quizId int32, categoryIds []int32) error
 
 	// May return ErrInternal or ErrNotFound on failure.
-	AddQuestion(ctx context.Context, quizId int32, text string, qtype string) (int32, error)
+	AddQuestion(ctx context.Context, question *models.Question) (int32, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddTextQuestionAnswer(ctx context.Context, answer *models.TextQuestionAnswer) (int32, error)
 
 	// May return ErrInternal or ErrNotFound on failure.
-	AddTextQuestionAnswer(ctx context.Context, qId int32, answer string) (int32, error)
+	AddNumericQuestionAnswer(ctx context.Context, answer *models.NumericQuestionAnswer) error
 
 	// May return ErrInternal or ErrNotFound on failure.
 	AddChoice(ctx context.Context, qId int32, text string, isCorrect bool) (int32, error)
 
+	// Corrects the answer key in place, the answers given to the choice are kept.
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateChoiceCorrect(ctx context.Context, id int32, isCorrect bool) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	RemoveTextQuestionAnswers(ctx context.Context, questionId int32) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateNumericQuestionAnswer(ctx context.Context, answer *models.NumericQuestionAnswer) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddOrderingItem(ctx context.Context, item *models.OrderingItem) (int32, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddClozeBlankOption(ctx context.Context, option *models.ClozeBlankOption) (int32, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddMatchingPair(ctx context.Context, pair *models.MatchingPair) (int32, error)
+
 	// May return ErrInternal or ErrNotFound on failure.
 	GetQuiz(ctx context.Context, id int32) (*models.Quiz, error)
 
@@ -45,7 +80,27 @@ type QuizRepository interface {
 	GetQuizQuestions(ctx context.Context, id int32) ([]*models.Question, error)
 
 	// May return ErrInternal or ErrNotFound on failure.
-	GetTextQuestionAnswer(ctx context.Context, questionId int32) (*models.TextQuestionAnswer, error)
+	GetQuizVersionQuestions(ctx context.Context, quizId int32, version int32) ([]*models.Question, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetQuestion(ctx context.Context, id int32) (*models.Question, error)
+
+	// Returns the questions of the bank version.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetBankQuestions(ctx context.Context, bankId int32, version int32) ([]*models.Question, error)
+
+	// Returns the questions the attempt received in their order.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetAttemptQuestions(ctx context.Context, attemptId int32) ([]*models.Question, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddAttemptQuestions(ctx context.Context, attemptId int32, questionIds []int32) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetTextQuestionAnswers(ctx context.Context, questionId int32) ([]*models.TextQuestionAnswer, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetNumericQuestionAnswer(ctx context.Context, questionId int32) (*models.NumericQuestionAnswer, error)
 
 	// May return ErrInternal or ErrNotFound on failure.
 	GetChoices(ctx context.Context, questionId int32) ([]*models.Choice, error)
@@ -53,35 +108,81 @@ type QuizRepository interface {
 	// May return ErrInternal or ErrNotFound on failure.
 	GetCorrectChoice(ctx context.Context, questionId int32) (*models.Choice, error)
 
+	// May return ErrInternal or ErrNotFound on failure.
+	GetOrderingItems(ctx context.Context, questionId int32) ([]*models.OrderingItem, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetClozeBlankOptions(ctx context.Context, questionId int32) ([]*models.ClozeBlankOption, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetMatchingPairs(ctx context.Context, questionId int32) ([]*models.MatchingPair, error)
+
 	// May return ErrInternal or ErrNotFound on failure.
 	DeleteQuiz(ctx context.Context, id int32) error
 
+	// Replaces the allowed question types in the database.
+	// May return ErrInternal on failure.
+	SetQuestionTypes(ctx context.Context, types []string) error
+
+	// Returns the unfinished attempt of the user on the quiz.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetOpenParticipationTime(ctx context.Context, userId int32, quizId int32) (*models.QuizParticipationTime, error)
+
+	// Returns unfinished attempts whose time limit together with the grace
+	// period has run out by the given time.
 	// May return ErrInternal or ErrNotFound on failure.
-	GetLastParticipationTime(ctx context.Context, userId int32) (*models.QuizParticipationTime, error)
+	GetExpiredParticipationTimes(ctx context.Context, grace time.Duration, now time.Time) ([]*models.QuizParticipationTime, error)
 
 	// May return ErrInternal or ErrNotFound on failure.
 	DeleteParticipationTime(ctx context.Context, id int32) error
 
+	// Finishes the attempt, an attempt which is finished already is left as it is.
 	// May return ErrInternal or ErrNotFound on failure.
 	UpdateParticipationTime(ctx context.Context, id int32, finishTime time.Time) error
 
 	// May return ErrInternal or ErrNotFound on failure.
-	AddParticipationTime(ctx context.Context, userId int32, quizId int32, startTime time.Time) (int32, error)
+	UpdateParticipationScore(ctx context.Context, id int32, score float32, points float32, maxPoints float32, passed *bool) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddParticipationTime(ctx context.Context, partTime *models.QuizParticipationTime) (int32, error)
+
+	// Counts a new attempt unless the user has maxCount of them already, nil
+	// maxCount sets no limit. Returns false when the attempt is not counted.
+	// May return ErrInternal or ErrNotFound on failure.
+	IncrementParticipationCount(ctx context.Context, userId int32, quizId int32, maxCount *int32) (bool, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetUserParticipations(ctx context.Context, userId int32, quizIds []int32) ([]*models.UserQuizParticipation, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	RemoveUserChoiceAnswers(ctx context.Context, questionId int32, attemptId int32) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddUserChoiceAnswer(ctx context.Context, attemptId int32, questionId int32, choiceId int32) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	RemoveUserTextAnswers(ctx context.Context, questionId int32, attemptId int32) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddUserTextAnswer(ctx context.Context, attemptId int32, questionId int32, text string) error
 
 	// May return ErrInternal or ErrNotFound on failure.
-	RemoveUserChoiceAnswers(ctx context.Context, questionId int32, userId int32) error
+	AddUserBlankAnswer(ctx context.Context, attemptId int32, questionId int32, blankNumber int32, text string) error
 
 	// May return ErrInternal or ErrNotFound on failure.
-	AddUserChoiceAnswer(ctx context.Context, userId int32, questionId int32, choiceId int32) error
+	RemoveUserOrderingAnswers(ctx context.Context, questionId int32, attemptId int32) error
 
 	// May return ErrInternal or ErrNotFound on failure.
-	RemoveUserTextAnswers(ctx context.Context, questionId int32, userId int32) error
+	AddUserOrderingAnswer(ctx context.Context, attemptId int32, questionId int32, itemId int32, position int32) error
 
 	// May return ErrInternal or ErrNotFound on failure.
-	AddUserTextAnswer(ctx context.Context, userId int32, questionId int32, text string) error
+	RemoveUserMatchingAnswers(ctx context.Context, questionId int32, attemptId int32) error
 
 	// May return ErrInternal or ErrNotFound on failure.
-	UpsertUserScore(ctx context.Context, userId int32, quizId int32, score float32, time time.Time) error
+	AddUserMatchingAnswer(ctx context.Context, attemptId int32, questionId int32, pairId int32, matchedPairId int32) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	UpsertUserScore(ctx context.Context, userId int32, quizId int32, score float32, points float32, maxPoints float32, provisional bool, time time.Time) error
 
 	// May return ErrInternal or ErrNotFound on failure.
 	GetQuizStatistics(ctx context.Context, quizIds []int32) ([]*models.QuizStatistics, error)
@@ -89,15 +190,160 @@ type QuizRepository interface {
 	// May return ErrInternal or ErrNotFound on failure.
 	GetUserScore(ctx context.Context, userId int32, quizId int32) (*models.UserQuizScore, error)
 
+	// May return ErrInternal or ErrNotFound on failure.
+	GetUserScores(ctx context.Context, userId int32, quizIds []int32) ([]*models.UserQuizScore, error)
+
 	// May return ErrInternal or ErrNotFound on failure.
 	GetQuizParticipationTime(ctx context.Context, userId int32, quizId int32) (*models.QuizParticipationTime, error)
 
 	// May return ErrInternal or ErrNotFound on failure.
-	GetUserTextAnswer(ctx context.Context, userId int32, questionId int32) (*models.TextAnswer, error)
+	GetParticipationTime(ctx context.Context, id int32) (*models.QuizParticipationTime, error)
+
+	// Same as GetParticipationTime, but locks the attempt until the end of
+	// the transaction.
+	// May return ErrInternal or ErrNotFound on failure.
+	LockParticipationTime(ctx context.Context, id int32) (*models.QuizParticipationTime, error)
+
+	// Returns the attempts of the user on the quiz, the latest first.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetUserParticipationTimes(ctx context.Context, userId int32, quizId int32) ([]*models.QuizParticipationTime, error)
+
+	// Returns the unfinished attempts of the quizzes closed before now - grace.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetClosedQuizAttempts(ctx context.Context, grace time.Duration, now time.Time) ([]*models.QuizParticipationTime, error)
+
+	// Publishes the drafts whose opening time has come and closes the published
+	// quizzes whose closing time passed more than grace ago.
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateScheduledQuizStatuses(ctx context.Context, grace time.Duration, now time.Time) error
 
+	// Returns the finished attempts of the quiz that received the question.
+	// Zero quiz id matches every quiz, zero question id every question.
 	// May return ErrInternal or ErrNotFound on failure.
-	GetUserChoiceAnswer(ctx context.Context, userId int32, questionId int32) (*models.ChoiceAnswer, error)
+	GetFinishedAttempts(ctx context.Context, quizId int32, questionId int32) ([]*models.QuizParticipationTime, error)
+
+	// Returns true when an attempt of the quiz received the question.
+	// May return ErrInternal or ErrNotFound on failure.
+	HasAttemptQuestion(ctx context.Context, quizId int32, questionId int32) (bool, error)
+
+	// Recalculates the statistics of the quiz from the user scores.
+	// May return ErrInternal or ErrNotFound on failure.
+	CalculateQuizStatistics(ctx context.Context, quizId int32) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetUserTextAnswer(ctx context.Context, attemptId int32, questionId int32) (*models.TextAnswer, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetUserBlankAnswers(ctx context.Context, attemptId int32, questionId int32) ([]*models.TextAnswer, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetUserChoiceAnswers(ctx context.Context, attemptId int32, questionId int32) ([]*models.ChoiceAnswer, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetUserOrderingAnswers(ctx context.Context, attemptId int32, questionId int32) ([]*models.OrderingAnswer, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetUserMatchingAnswers(ctx context.Context, attemptId int32, questionId int32) ([]*models.MatchingAnswer, error)
 
 	// May return ErrInternal or ErrNotFound on failure.
 	GetChoice(ctx context.Context, id int32) (*models.Choice, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddQuestionBank(ctx context.Context, bank *models.QuestionBank) (int32, error)
+
+	// Updates the bank and moves it to the next version.
+	// May return ErrInternal or ErrNotFound on failure.
+	EditQuestionBank(ctx context.Context, id int32, title string, desc string, isShared bool) (int32, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetQuestionBank(ctx context.Context, id int32) (*models.QuestionBank, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetAllQuestionBanks(ctx context.Context) ([]*models.QuestionBank, error)
+
+	// Returns the banks of the user and the banks shared by other authors.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetUserQuestionBanks(ctx context.Context, userId int32) ([]*models.QuestionBank, error)
+
+	// Returns how many questions the current versions of the banks have
+	// and their tags in alphabetical order.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetQuestionBankContents(ctx context.Context, bankIds []int32) ([]*models.QuestionBankContents, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddDrawRule(ctx context.Context, rule *models.QuizDrawRule) error
+
+	// Returns the draw rules of the quiz version in their order.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetDrawRules(ctx context.Context, quizId int32, version int32) ([]*models.QuizDrawRule, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddQuestionVariable(ctx context.Context, variable *models.QuestionVariable) error
+
+	// Returns the variables of the questions ordered by name.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetQuestionVariables(ctx context.Context, questionIds []int32) ([]*models.QuestionVariable, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddAttemptVariables(ctx context.Context, variables []*models.AttemptVariable) error
+
+	// Returns the values generated for the question in the attempt.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetAttemptVariables(ctx context.Context, attemptId int32, questionId int32) ([]*models.AttemptVariable, error)
+
+	// Tokens already stored for the question in the attempt are kept.
+	// May return ErrInternal or ErrNotFound on failure.
+	AddAttemptItemTokens(ctx context.Context, tokens []*models.AttemptItemToken) error
+
+	// Returns the tokens standing in for the items of the question in the attempt.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetAttemptItemTokens(ctx context.Context, attemptId int32, questionId int32) ([]*models.AttemptItemToken, error)
+
+	// Puts the answer into the manual grading queue unless it is there already.
+	// May return ErrInternal or ErrNotFound on failure.
+	AddManualGrade(ctx context.Context, attemptId int32, questionId int32) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetManualGrade(ctx context.Context, attemptId int32, questionId int32) (*models.ManualGrade, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateManualGrade(ctx context.Context, grade *models.ManualGrade) error
+
+	// Returns the ungraded answers of finished attempts, oldest first.
+	// Zero author id returns the answers to the quizzes of all authors.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetPendingReviews(ctx context.Context, authorId int32) ([]*models.PendingReview, error)
+
+	// Returns how many answers of the attempts wait for grading.
+	// May return ErrInternal or ErrNotFound on failure.
+	CountPendingReviews(ctx context.Context, attemptIds []int32) (int, error)
+
+	// Does nothing when the user already has a certificate for the quiz.
+	// May return ErrInternal or ErrNotFound on failure.
+	AddCertificate(ctx context.Context, certificate *models.Certificate) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetCertificate(ctx context.Context, code string) (*models.Certificate, error)
+
+	// Returns the certificates of the user, newest first.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetUserCertificates(ctx context.Context, userId int32) ([]*models.Certificate, error)
+
+	// Removes the certificate issued for the attempt, returns false when
+	// the attempt has none.
+	// May return ErrInternal or ErrNotFound on failure.
+	RemoveAttemptCertificate(ctx context.Context, attemptId int32) (bool, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddQuestionExplanation(ctx context.Context, questionId int32, explanation string) error
+
+	// May return ErrInternal or ErrNotFound on failure.
+	GetQuestionExplanations(ctx context.Context, questionIds []int32) ([]*models.QuestionExplanation, error)
+
+	// May return ErrInternal or ErrNotFound on failure.
+	AddChoiceFeedback(ctx context.Context, choiceId int32, feedback string) error
+
+	// Returns the feedback of the choices of the question.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetChoiceFeedback(ctx context.Context, questionId int32) ([]*models.ChoiceFeedback, error)
 }
@@ -43,3 +43,16 @@ func (repo *SqlActionsRepository) GetAllActions(ctx context.Context) ([]*models.
 
 	return allActions, nil
 }
+
+// Nil user id records an action made outside of the site.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlActionsRepository) AddAction(ctx context.Context, userId *int32, description string) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		"INSERT INTO user_actions (user_id, action_description) VALUES ($1, $2)",
+		userId, description)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
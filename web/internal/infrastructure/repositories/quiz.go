@@ -85,21 +85,18 @@ func (repo *SqlQuizRepository) GetCategoriesPairs(ctx context.Context, quizIds [
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) GetAllQuizzes(ctx context.Context, categoryId int32) ([]*models.Quiz, error) {
-	var query string
-	if categoryId == 0 {
-		query = "SELECT id, author_id, title, description, created_at, updated_at FROM quizzes"
-	} else {
-		query = `
-            SELECT q.id, q.author_id, q.title, q.description, q.created_at, q.updated_at 
-            FROM quizzes q 
-            JOIN quiz_categories qc ON q.id = qc.quiz_id 
-            WHERE qc.category_id = $1`
-	}
-	args := []any{}
-	if categoryId != 0 {
-		args = append(args, categoryId)
-	}
+func (repo *SqlQuizRepository) GetAllQuizzes(ctx context.Context, categoryId int32, viewerId int32, showHidden bool) ([]*models.Quiz, error) {
+	// Drafts and archived quizzes are visible only to their authors and with showHidden
+	query := `
+            SELECT q.id, q.author_id, q.title, q.description, q.version, q.created_at, q.updated_at,
+            q.time_limit_seconds, q.max_attempts, q.cooldown_seconds, q.score_policy,
+            q.shuffle_questions, q.shuffle_choices, q.status, q.opens_at, q.closes_at, q.reveal_policy,
+            q.pass_mark
+            FROM quizzes q
+            WHERE ($1 = 0 OR q.id IN (SELECT quiz_id FROM quiz_categories WHERE category_id = $1))
+            AND (q.status IN ('published', 'closed') OR q.author_id = $2 OR $3)
+            ORDER BY q.id`
+	args := []any{categoryId, viewerId, showHidden}
 	rows, err := repo.DBProvider.QueryContext(
 		ctx,
 		query,
@@ -115,7 +112,10 @@ func (repo *SqlQuizRepository) GetAllQuizzes(ctx context.Context, categoryId int
 		var quiz models.Quiz
 		err = rows.Scan(
 			&quiz.Id, &quiz.AuthorId, &quiz.Title,
-			&quiz.Description, &quiz.CreatedAt, &quiz.UpdatedAt)
+			&quiz.Description, &quiz.Version, &quiz.CreatedAt, &quiz.UpdatedAt,
+			&quiz.TimeLimitSeconds, &quiz.MaxAttempts, &quiz.CooldownSeconds, &quiz.ScorePolicy,
+			&quiz.ShuffleQuestions, &quiz.ShuffleChoices, &quiz.Status, &quiz.OpensAt, &quiz.ClosesAt,
+			&quiz.RevealPolicy, &quiz.PassMark)
 		if err != nil {
 			return nil, &apperrors.ErrInternal{Message: err.Error()}
 		}
@@ -139,6 +139,89 @@ func (repo *SqlQuizRepository) AddQuiz(ctx context.Context, title string, desc s
 	return id, nil
 }
 
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) EditQuiz(ctx context.Context, id int32, title string, desc string) (int32, error) {
+	var version int32
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`UPDATE quizzes SET
+		title = $1, description = $2, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING version`,
+		title, desc, id).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, &apperrors.ErrNotFound{Message: "quiz not found"}
+		} else {
+			return 0, &apperrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return version, nil
+}
+
+// Updates the settings of the quiz which are not versioned.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) UpdateQuizSettings(ctx context.Context, quiz *models.Quiz) error {
+	res, err := repo.DBProvider.ExecContext(
+		ctx,
+		`UPDATE quizzes SET
+		time_limit_seconds = $1, max_attempts = $2, cooldown_seconds = $3, score_policy = $4,
+		shuffle_questions = $5, shuffle_choices = $6, status = $7, opens_at = $8, closes_at = $9,
+		reveal_policy = $10, pass_mark = $11
+		WHERE id = $12`,
+		quiz.TimeLimitSeconds, quiz.MaxAttempts, quiz.CooldownSeconds, quiz.ScorePolicy,
+		quiz.ShuffleQuestions, quiz.ShuffleChoices, quiz.Status, quiz.OpensAt, quiz.ClosesAt,
+		quiz.RevealPolicy, quiz.PassMark, quiz.Id)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	if rowsAffected == 0 {
+		return &apperrors.ErrNotFound{Message: "quiz not found"}
+	}
+
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddQuizVersion(ctx context.Context, quizId int32, version int32, title string, desc string) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		"INSERT INTO quiz_versions (quiz_id, version, title, description) VALUES ($1, $2, $3, $4)",
+		quizId, version, title, desc)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetQuizVersion(ctx context.Context, quizId int32, version int32) (*models.QuizVersion, error) {
+	quizVersion := &models.QuizVersion{}
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`SELECT quiz_id, version, title, description, created_at
+		FROM quiz_versions
+		WHERE quiz_id = $1 AND version = $2`,
+		quizId, version).Scan(
+		&quizVersion.QuizId, &quizVersion.Version, &quizVersion.Title,
+		&quizVersion.Description, &quizVersion.CreatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &apperrors.ErrNotFound{Message: "content not found"}
+		} else {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	return quizVersion, nil
+}
+
 // May return ErrInternal or ErrNotFound on failure.
 func (repo *SqlQuizRepository) RemoveQuizCategories(ctx context.Context, quizId int32) error {
 	_, err := repo.DBProvider.ExecContext(
@@ -203,12 +286,19 @@ func (repo *SqlQuizRepository) AddQuizCategories(ctx context.Context, quizId int
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) AddQuestion(ctx context.Context, quizId int32, text string, qtype string) (int32, error) {
+func (repo *SqlQuizRepository) AddQuestion(ctx context.Context, question *models.Question) (int32, error) {
 	var id int32
 	err := repo.DBProvider.QueryRowContext(
 		ctx,
-		"INSERT INTO questions (quiz_id, question_text, question_type) VALUES ($1, $2, $3) RETURNING id",
-		quizId, text, qtype).Scan(&id)
+		`INSERT INTO questions
+		(quiz_id, bank_id, version, tag, category_id, question_text, question_type, scoring_mode,
+		points, negative_points, answer_formula)
+		VALUES (NULLIF($1, 0), NULLIF($2, 0), $3, NULLIF($4, ''), NULLIF($5, 0), $6, $7, NULLIF($8, ''),
+		$9, $10, NULLIF($11, ''))
+		RETURNING id`,
+		question.QuizId, question.BankId, question.Version, question.Tag, question.CategoryId,
+		question.QuestionText, question.QuestionType, question.ScoringMode,
+		question.Points, question.NegativePoints, question.AnswerFormula).Scan(&id)
 	if err != nil {
 		return 0, &apperrors.ErrInternal{Message: err.Error()}
 	}
@@ -216,18 +306,76 @@ func (repo *SqlQuizRepository) AddQuestion(ctx context.Context, quizId int32, te
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) AddTextQuestionAnswer(ctx context.Context, qId int32, answer string) (int32, error) {
+func (repo *SqlQuizRepository) AddTextQuestionAnswer(ctx context.Context, answer *models.TextQuestionAnswer) (int32, error) {
 	var id int32
 	err := repo.DBProvider.QueryRowContext(
 		ctx,
-		"INSERT INTO text_question_answers (question_id, right_answer) VALUES ($1, $2) RETURNING question_id",
-		qId, answer).Scan(&id)
+		`INSERT INTO text_question_answers
+		(question_id, blank_number, right_answer, match_mode, max_distance)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		answer.QuestionId, answer.BlankNumber, answer.RightAnswer,
+		answer.MatchMode, answer.MaxDistance).Scan(&id)
 	if err != nil {
 		return 0, &apperrors.ErrInternal{Message: err.Error()}
 	}
 	return id, nil
 }
 
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddNumericQuestionAnswer(ctx context.Context, answer *models.NumericQuestionAnswer) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`INSERT INTO numeric_question_answers
+		(question_id, target_value, tolerance, tolerance_mode, units)
+		VALUES ($1, $2, $3, $4, $5)`,
+		answer.QuestionId, answer.TargetValue, answer.Tolerance,
+		answer.ToleranceMode, pq.Array(answer.Units))
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Corrects the answer key in place, the answers given to the choice are kept.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) UpdateChoiceCorrect(ctx context.Context, id int32, isCorrect bool) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`UPDATE choices SET is_correct = $2 WHERE id = $1`,
+		id, isCorrect)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) RemoveTextQuestionAnswers(ctx context.Context, questionId int32) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`DELETE FROM text_question_answers WHERE question_id = $1`,
+		questionId)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) UpdateNumericQuestionAnswer(ctx context.Context, answer *models.NumericQuestionAnswer) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`UPDATE numeric_question_answers
+		SET target_value = $2, tolerance = $3, tolerance_mode = $4, units = $5
+		WHERE question_id = $1`,
+		answer.QuestionId, answer.TargetValue, answer.Tolerance,
+		answer.ToleranceMode, pq.Array(answer.Units))
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
 // May return ErrInternal or ErrNotFound on failure.
 func (repo *SqlQuizRepository) AddChoice(ctx context.Context, qId int32, text string, isCorrect bool) (int32, error) {
 	var id int32
@@ -241,17 +389,63 @@ func (repo *SqlQuizRepository) AddChoice(ctx context.Context, qId int32, text st
 	return id, nil
 }
 
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddOrderingItem(ctx context.Context, item *models.OrderingItem) (int32, error) {
+	var id int32
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		"INSERT INTO ordering_items (question_id, item_text, position) VALUES ($1, $2, $3) RETURNING id",
+		item.QuestionId, item.ItemText, item.Position).Scan(&id)
+	if err != nil {
+		return 0, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return id, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddClozeBlankOption(ctx context.Context, option *models.ClozeBlankOption) (int32, error) {
+	var id int32
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`INSERT INTO cloze_blank_options
+		(question_id, blank_number, option_text, is_correct)
+		VALUES ($1, $2, $3, $4) RETURNING id`,
+		option.QuestionId, option.BlankNumber, option.OptionText, option.IsCorrect).Scan(&id)
+	if err != nil {
+		return 0, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return id, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddMatchingPair(ctx context.Context, pair *models.MatchingPair) (int32, error) {
+	var id int32
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		"INSERT INTO matching_pairs (question_id, left_text, right_text, position) VALUES ($1, $2, $3, $4) RETURNING id",
+		pair.QuestionId, pair.LeftText, pair.RightText, pair.Position).Scan(&id)
+	if err != nil {
+		return 0, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return id, nil
+}
+
 // May return ErrInternal or ErrNotFound on failure.
 func (repo *SqlQuizRepository) GetQuiz(ctx context.Context, id int32) (*models.Quiz, error) {
 	quiz := &models.Quiz{}
 	err := repo.DBProvider.QueryRowContext(
 		ctx,
-		`SELECT id, author_id, title, description, created_at, updated_at
+		`SELECT id, author_id, title, description, version, created_at, updated_at,
+		time_limit_seconds, max_attempts, cooldown_seconds, score_policy,
+		shuffle_questions, shuffle_choices, status, opens_at, closes_at, reveal_policy, pass_mark
 		FROM quizzes 
 		WHERE id = $1 `,
 		id).Scan(
 		&quiz.Id, &quiz.AuthorId, &quiz.Title,
-		&quiz.Description, &quiz.CreatedAt, &quiz.UpdatedAt)
+		&quiz.Description, &quiz.Version, &quiz.CreatedAt, &quiz.UpdatedAt,
+		&quiz.TimeLimitSeconds, &quiz.MaxAttempts, &quiz.CooldownSeconds, &quiz.ScorePolicy,
+		&quiz.ShuffleQuestions, &quiz.ShuffleChoices, &quiz.Status, &quiz.OpensAt, &quiz.ClosesAt,
+		&quiz.RevealPolicy, &quiz.PassMark)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -264,17 +458,105 @@ func (repo *SqlQuizRepository) GetQuiz(ctx context.Context, id int32) (*models.Q
 	return quiz, nil
 }
 
+// Returns the questions of the current quiz version.
 // May return ErrInternal or ErrNotFound on failure.
 func (repo *SqlQuizRepository) GetQuizQuestions(ctx context.Context, id int32) ([]*models.Question, error) {
 	query :=
 		`SELECT
-		id, quiz_id, question_text, question_type
-		FROM questions WHERE quiz_id = $1`
+		id, COALESCE(quiz_id, 0), COALESCE(bank_id, 0), version, COALESCE(tag, ''), COALESCE(category_id, 0),
+		question_text, question_type, COALESCE(scoring_mode, ''), points, negative_points,
+		COALESCE(answer_formula, '')
+		FROM questions
+		WHERE quiz_id = $1 AND version = (SELECT version FROM quizzes WHERE id = $1)
+		ORDER BY id`
+
+	return repo.queryQuestions(ctx, query, id)
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetQuizVersionQuestions(ctx context.Context, quizId int32, version int32) ([]*models.Question, error) {
+	query :=
+		`SELECT
+		id, COALESCE(quiz_id, 0), COALESCE(bank_id, 0), version, COALESCE(tag, ''), COALESCE(category_id, 0),
+		question_text, question_type, COALESCE(scoring_mode, ''), points, negative_points,
+		COALESCE(answer_formula, '')
+		FROM questions
+		WHERE quiz_id = $1 AND version = $2
+		ORDER BY id`
+
+	return repo.queryQuestions(ctx, query, quizId, version)
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetQuestion(ctx context.Context, id int32) (*models.Question, error) {
+	query :=
+		`SELECT
+		id, COALESCE(quiz_id, 0), COALESCE(bank_id, 0), version, COALESCE(tag, ''), COALESCE(category_id, 0),
+		question_text, question_type, COALESCE(scoring_mode, ''), points, negative_points,
+		COALESCE(answer_formula, '')
+		FROM questions
+		WHERE id = $1`
+
+	questions, err := repo.queryQuestions(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, &apperrors.ErrNotFound{Message: "content not found"}
+	}
+	return questions[0], nil
+}
+
+// Returns the questions of the bank version.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetBankQuestions(ctx context.Context, bankId int32, version int32) ([]*models.Question, error) {
+	query :=
+		`SELECT
+		id, COALESCE(quiz_id, 0), COALESCE(bank_id, 0), version, COALESCE(tag, ''), COALESCE(category_id, 0),
+		question_text, question_type, COALESCE(scoring_mode, ''), points, negative_points,
+		COALESCE(answer_formula, '')
+		FROM questions
+		WHERE bank_id = $1 AND version = $2
+		ORDER BY id`
+
+	return repo.queryQuestions(ctx, query, bankId, version)
+}
+
+// Returns the questions the attempt received in their order.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetAttemptQuestions(ctx context.Context, attemptId int32) ([]*models.Question, error) {
+	query :=
+		`SELECT
+		q.id, COALESCE(q.quiz_id, 0), COALESCE(q.bank_id, 0), q.version, COALESCE(q.tag, ''), COALESCE(q.category_id, 0),
+		q.question_text, q.question_type, COALESCE(q.scoring_mode, ''), q.points, q.negative_points,
+		COALESCE(q.answer_formula, '')
+		FROM attempt_questions aq
+		JOIN questions q ON q.id = aq.question_id
+		WHERE aq.attempt_id = $1
+		ORDER BY aq.position`
+
+	return repo.queryQuestions(ctx, query, attemptId)
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddAttemptQuestions(ctx context.Context, attemptId int32, questionIds []int32) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`INSERT INTO attempt_questions (attempt_id, question_id, position)
+		SELECT $1, t.question_id, t.position
+		FROM unnest($2::int[]) WITH ORDINALITY AS t(question_id, position)`,
+		attemptId, pq.Array(questionIds))
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
 
+func (repo *SqlQuizRepository) queryQuestions(ctx context.Context, query string, args ...any) ([]*models.Question, error) {
 	rows, err := repo.DBProvider.QueryContext(
 		ctx,
 		query,
-		id,
+		args...,
 	)
 	if err != nil {
 		return nil, &apperrors.ErrInternal{Message: err.Error()}
@@ -285,7 +567,9 @@ func (repo *SqlQuizRepository) GetQuizQuestions(ctx context.Context, id int32) (
 	for rows.Next() {
 		var question models.Question
 		err = rows.Scan(
-			&question.Id, &question.QuizId, &question.QuestionText, &question.QuestionType)
+			&question.Id, &question.QuizId, &question.BankId, &question.Version, &question.Tag, &question.CategoryId,
+			&question.QuestionText, &question.QuestionType, &question.ScoringMode,
+			&question.Points, &question.NegativePoints, &question.AnswerFormula)
 		if err != nil {
 			return nil, &apperrors.ErrInternal{Message: err.Error()}
 		}
@@ -297,15 +581,50 @@ func (repo *SqlQuizRepository) GetQuizQuestions(ctx context.Context, id int32) (
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) GetTextQuestionAnswer(ctx context.Context, questionId int32) (*models.TextQuestionAnswer, error) {
-	answer := &models.TextQuestionAnswer{}
+func (repo *SqlQuizRepository) GetTextQuestionAnswers(ctx context.Context, questionId int32) ([]*models.TextQuestionAnswer, error) {
+	query :=
+		`SELECT
+		id, question_id, blank_number, right_answer, match_mode, max_distance
+		FROM text_question_answers WHERE question_id = $1
+		ORDER BY id`
+
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		query,
+		questionId,
+	)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allAnswers := make([]*models.TextQuestionAnswer, 0)
+	for rows.Next() {
+		var answer models.TextQuestionAnswer
+		err = rows.Scan(
+			&answer.Id, &answer.QuestionId, &answer.BlankNumber,
+			&answer.RightAnswer, &answer.MatchMode, &answer.MaxDistance)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allAnswers = append(allAnswers, &answer)
+	}
+
+	return allAnswers, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetNumericQuestionAnswer(ctx context.Context, questionId int32) (*models.NumericQuestionAnswer, error) {
+	answer := &models.NumericQuestionAnswer{}
 	err := repo.DBProvider.QueryRowContext(
 		ctx,
-		`SELECT question_id, right_answer
-		FROM text_question_answers 
+		`SELECT question_id, target_value, tolerance, tolerance_mode, units
+		FROM numeric_question_answers
 		WHERE question_id = $1 `,
 		questionId).Scan(
-		&answer.QuestionId, &answer.RightAnswer)
+		&answer.QuestionId, &answer.TargetValue, &answer.Tolerance,
+		&answer.ToleranceMode, pq.Array(&answer.Units))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -349,75 +668,234 @@ func (repo *SqlQuizRepository) GetChoices(ctx context.Context, questionId int32)
 	return allChoices, nil
 }
 
+// Returns the items in the right order.
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) DeleteQuiz(ctx context.Context, id int32) error {
-	_, err := repo.DBProvider.ExecContext(
+func (repo *SqlQuizRepository) GetOrderingItems(ctx context.Context, questionId int32) ([]*models.OrderingItem, error) {
+	query :=
+		`SELECT
+		id, question_id, item_text, position
+		FROM ordering_items WHERE question_id = $1
+		ORDER BY position`
+
+	rows, err := repo.DBProvider.QueryContext(
 		ctx,
-		`DELETE FROM quizzes WHERE
-		id = $1`, id)
+		query,
+		questionId,
+	)
 	if err != nil {
-		return &apperrors.ErrInternal{Message: err.Error()}
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
 	}
+	defer rows.Close()
 
-	return nil
+	allItems := make([]*models.OrderingItem, 0)
+	for rows.Next() {
+		var item models.OrderingItem
+		err = rows.Scan(
+			&item.Id, &item.QuestionId, &item.ItemText, &item.Position)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allItems = append(allItems, &item)
+	}
+
+	return allItems, nil
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) GetCorrectChoice(ctx context.Context, questionId int32) (*models.Choice, error) {
-	choice := &models.Choice{}
-	err := repo.DBProvider.QueryRowContext(
-		ctx,
-		`SELECT id, question_id, choice_text, is_correct
-		FROM choices 
-		WHERE question_id = $1 AND is_correct = true `,
-		questionId).Scan(
-		&choice.Id, &choice.QuestionId, &choice.ChoiceText,
-		&choice.IsCorrect)
+func (repo *SqlQuizRepository) GetClozeBlankOptions(ctx context.Context, questionId int32) ([]*models.ClozeBlankOption, error) {
+	query :=
+		`SELECT
+		id, question_id, blank_number, option_text, is_correct
+		FROM cloze_blank_options WHERE question_id = $1
+		ORDER BY blank_number, id`
 
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		query,
+		questionId,
+	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, &apperrors.ErrNotFound{Message: "content not found"}
-		} else {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allOptions := make([]*models.ClozeBlankOption, 0)
+	for rows.Next() {
+		var option models.ClozeBlankOption
+		err = rows.Scan(
+			&option.Id, &option.QuestionId, &option.BlankNumber,
+			&option.OptionText, &option.IsCorrect)
+		if err != nil {
 			return nil, &apperrors.ErrInternal{Message: err.Error()}
 		}
+
+		allOptions = append(allOptions, &option)
 	}
 
-	return choice, nil
+	return allOptions, nil
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) GetLastParticipationTime(ctx context.Context, userId int32) (*models.QuizParticipationTime, error) {
-	partTime := &models.QuizParticipationTime{}
-	err := repo.DBProvider.QueryRowContext(
-		ctx,
-		`SELECT id, participation_number, user_id, quiz_id, started_at, finished_at
-		FROM quiz_participation_times
-		WHERE user_id = $1
-		AND participation_number = (
-			SELECT MAX(participation_number)
-			FROM quiz_participation_times
-			WHERE user_id = $1
-		);`,
-		userId).Scan(
-		&partTime.Id, &partTime.ParticipationNumber, &partTime.UserId,
-		&partTime.QuizId, &partTime.StartedAt, &partTime.FinishedAt)
+func (repo *SqlQuizRepository) GetMatchingPairs(ctx context.Context, questionId int32) ([]*models.MatchingPair, error) {
+	query :=
+		`SELECT
+		id, question_id, left_text, right_text, position
+		FROM matching_pairs WHERE question_id = $1
+		ORDER BY position`
 
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		query,
+		questionId,
+	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, &apperrors.ErrNotFound{Message: "content not found"}
-		} else {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allPairs := make([]*models.MatchingPair, 0)
+	for rows.Next() {
+		var pair models.MatchingPair
+		err = rows.Scan(
+			&pair.Id, &pair.QuestionId, &pair.LeftText, &pair.RightText, &pair.Position)
+		if err != nil {
 			return nil, &apperrors.ErrInternal{Message: err.Error()}
 		}
+
+		allPairs = append(allPairs, &pair)
 	}
 
-	return partTime, nil
+	return allPairs, nil
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) DeleteParticipationTime(ctx context.Context, id int32) error {
+func (repo *SqlQuizRepository) DeleteQuiz(ctx context.Context, id int32) error {
 	_, err := repo.DBProvider.ExecContext(
 		ctx,
-		`DELETE FROM quiz_participation_times WHERE
+		`DELETE FROM quizzes WHERE
+		id = $1`, id)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// Replaces the allowed question types in the database.
+// May return ErrInternal on failure.
+func (repo *SqlQuizRepository) SetQuestionTypes(ctx context.Context, types []string) error {
+	quoted := make([]string, len(types))
+	for i, t := range types {
+		quoted[i] = pq.QuoteLiteral(t)
+	}
+
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`ALTER TABLE questions
+			DROP CONSTRAINT IF EXISTS questions_question_type_check,
+			ADD CONSTRAINT questions_question_type_check
+			CHECK (question_type IN (%s))`,
+			strings.Join(quoted, ", ")))
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetCorrectChoice(ctx context.Context, questionId int32) (*models.Choice, error) {
+	choice := &models.Choice{}
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`SELECT id, question_id, choice_text, is_correct
+		FROM choices 
+		WHERE question_id = $1 AND is_correct = true `,
+		questionId).Scan(
+		&choice.Id, &choice.QuestionId, &choice.ChoiceText,
+		&choice.IsCorrect)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &apperrors.ErrNotFound{Message: "content not found"}
+		} else {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	return choice, nil
+}
+
+// Returns the unfinished attempt of the user on the quiz.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetOpenParticipationTime(ctx context.Context, userId int32, quizId int32) (*models.QuizParticipationTime, error) {
+	partTime := &models.QuizParticipationTime{}
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`SELECT id, participation_number, user_id, quiz_id, quiz_version, started_at, finished_at,
+		score, points, max_points, question_seed, choice_seed, passed
+		FROM quiz_participation_times
+		WHERE user_id = $1 AND quiz_id = $2 AND finished_at IS NULL`,
+		userId, quizId).Scan(
+		&partTime.Id, &partTime.ParticipationNumber, &partTime.UserId,
+		&partTime.QuizId, &partTime.QuizVersion, &partTime.StartedAt, &partTime.FinishedAt,
+		&partTime.Score, &partTime.Points, &partTime.MaxPoints,
+		&partTime.QuestionSeed, &partTime.ChoiceSeed, &partTime.Passed)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &apperrors.ErrNotFound{Message: "content not found"}
+		} else {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	return partTime, nil
+}
+
+// Returns unfinished attempts whose time limit together with the grace
+// period has run out by the given time.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetExpiredParticipationTimes(ctx context.Context, grace time.Duration, now time.Time) ([]*models.QuizParticipationTime, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT pt.id, pt.participation_number, pt.user_id, pt.quiz_id, pt.quiz_version, pt.started_at, pt.finished_at,
+		pt.score, pt.points, pt.max_points, pt.question_seed, pt.choice_seed, pt.passed
+		FROM quiz_participation_times pt
+		JOIN quizzes q ON q.id = pt.quiz_id
+		WHERE pt.finished_at IS NULL
+		AND q.time_limit_seconds IS NOT NULL
+		AND pt.started_at + make_interval(secs => q.time_limit_seconds + $1) < $2`,
+		grace.Seconds(), now)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	partTimes := make([]*models.QuizParticipationTime, 0)
+	for rows.Next() {
+		partTime := &models.QuizParticipationTime{}
+		err = rows.Scan(
+			&partTime.Id, &partTime.ParticipationNumber, &partTime.UserId,
+			&partTime.QuizId, &partTime.QuizVersion, &partTime.StartedAt, &partTime.FinishedAt,
+			&partTime.Score, &partTime.Points, &partTime.MaxPoints,
+			&partTime.QuestionSeed, &partTime.ChoiceSeed, &partTime.Passed)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+		partTimes = append(partTimes, partTime)
+	}
+
+	return partTimes, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) DeleteParticipationTime(ctx context.Context, id int32) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`DELETE FROM quiz_participation_times WHERE
 		id = $1`, id)
 	if err != nil {
 		return &apperrors.ErrInternal{Message: err.Error()}
@@ -426,13 +904,14 @@ func (repo *SqlQuizRepository) DeleteParticipationTime(ctx context.Context, id i
 	return nil
 }
 
+// Finishes the attempt, an attempt which is finished already is left as it is.
 // May return ErrInternal or ErrNotFound on failure.
 func (repo *SqlQuizRepository) UpdateParticipationTime(ctx context.Context, id int32, finishTime time.Time) error {
 	res, err := repo.DBProvider.ExecContext(
 		ctx,
 		`UPDATE quiz_participation_times SET
 		finished_at = $1
-		WHERE id = $2`,
+		WHERE id = $2 AND finished_at IS NULL`,
 		finishTime, id)
 	if err != nil {
 		return &apperrors.ErrInternal{Message: err.Error()}
@@ -443,38 +922,115 @@ func (repo *SqlQuizRepository) UpdateParticipationTime(ctx context.Context, id i
 		return &apperrors.ErrInternal{Message: err.Error()}
 	}
 	if rowsAffected == 0 {
-		return &apperrors.ErrNotFound{Message: "news not found"}
+		return &apperrors.ErrNotFound{Message: "attempt already finished"}
+	}
+
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) UpdateParticipationScore(ctx context.Context, id int32, score float32, points float32, maxPoints float32, passed *bool) error {
+	res, err := repo.DBProvider.ExecContext(
+		ctx,
+		`UPDATE quiz_participation_times SET
+		score = $1, points = $2, max_points = $3, passed = $4
+		WHERE id = $5`,
+		score, points, maxPoints, passed, id)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	if rowsAffected == 0 {
+		return &apperrors.ErrNotFound{Message: "attempt not found"}
 	}
 
 	return nil
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) AddParticipationTime(ctx context.Context, userId int32, quizId int32, startTime time.Time) (int32, error) {
+func (repo *SqlQuizRepository) AddParticipationTime(ctx context.Context, partTime *models.QuizParticipationTime) (int32, error) {
 	var id int32
 	err := repo.DBProvider.QueryRowContext(
 		ctx,
-		`INSERT INTO quiz_participation_times (user_id, quiz_id, started_at, participation_number)
+		`INSERT INTO quiz_participation_times (user_id, quiz_id, quiz_version, started_at, participation_number,
+			question_seed, choice_seed)
 		VALUES (
 			$1,
 			$2,
+			(SELECT version FROM quizzes WHERE id = $2),
 			$3,
-			COALESCE((SELECT MAX(participation_number) FROM quiz_participation_times WHERE user_id = $1), 0) + 1
+			COALESCE((SELECT MAX(participation_number) FROM quiz_participation_times WHERE user_id = $1 AND quiz_id = $2), 0) + 1,
+			$4,
+			$5
 		)
 		RETURNING id;`,
-		userId, quizId, startTime).Scan(&id)
+		partTime.UserId, partTime.QuizId, partTime.StartedAt,
+		partTime.QuestionSeed, partTime.ChoiceSeed).Scan(&id)
 	if err != nil {
 		return 0, &apperrors.ErrInternal{Message: err.Error()}
 	}
 	return id, nil
 }
 
+// Counts a new attempt unless the user has maxCount of them already, nil
+// maxCount sets no limit. Returns false when the attempt is not counted.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) IncrementParticipationCount(ctx context.Context, userId int32, quizId int32, maxCount *int32) (bool, error) {
+	var count int32
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`INSERT INTO user_quiz_participations (user_id, quiz_id, participation_count)
+		SELECT $1, $2, 1
+		WHERE $3::int IS NULL OR $3::int > 0
+		ON CONFLICT (user_id, quiz_id)
+		DO UPDATE SET participation_count = user_quiz_participations.participation_count + 1
+		WHERE $3::int IS NULL OR user_quiz_participations.participation_count < $3::int
+		RETURNING participation_count`,
+		userId, quizId, maxCount).Scan(&count)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return true, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetUserParticipations(ctx context.Context, userId int32, quizIds []int32) ([]*models.UserQuizParticipation, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT user_id, quiz_id, participation_count
+		FROM user_quiz_participations
+		WHERE user_id = $1 AND quiz_id = ANY($2)`,
+		userId, pq.Array(quizIds))
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	participations := make([]*models.UserQuizParticipation, 0)
+	for rows.Next() {
+		participation := &models.UserQuizParticipation{}
+		err = rows.Scan(&participation.UserId, &participation.QuizId, &participation.ParticipationCount)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+		participations = append(participations, participation)
+	}
+
+	return participations, nil
+}
+
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) RemoveUserChoiceAnswers(ctx context.Context, questionId int32, userId int32) error {
+func (repo *SqlQuizRepository) RemoveUserChoiceAnswers(ctx context.Context, questionId int32, attemptId int32) error {
 	_, err := repo.DBProvider.ExecContext(
 		ctx,
 		`DELETE FROM choice_answers WHERE
-		question_id = $1 AND user_id = $2`, questionId, userId)
+		question_id = $1 AND attempt_id = $2`, questionId, attemptId)
 	if err != nil {
 		return &apperrors.ErrInternal{Message: err.Error()}
 	}
@@ -483,12 +1039,12 @@ func (repo *SqlQuizRepository) RemoveUserChoiceAnswers(ctx context.Context, ques
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) AddUserChoiceAnswer(ctx context.Context, userId int32, questionId int32, choiceId int32) error {
+func (repo *SqlQuizRepository) AddUserChoiceAnswer(ctx context.Context, attemptId int32, questionId int32, choiceId int32) error {
 	var id int32
 	err := repo.DBProvider.QueryRowContext(
 		ctx,
-		"INSERT INTO choice_answers (user_id, question_id, choice_id) VALUES ($1, $2, $3) RETURNING user_id",
-		userId, questionId, choiceId).Scan(&id)
+		"INSERT INTO choice_answers (attempt_id, question_id, choice_id) VALUES ($1, $2, $3) RETURNING attempt_id",
+		attemptId, questionId, choiceId).Scan(&id)
 	if err != nil {
 		return &apperrors.ErrInternal{Message: err.Error()}
 	}
@@ -496,11 +1052,11 @@ func (repo *SqlQuizRepository) AddUserChoiceAnswer(ctx context.Context, userId i
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) RemoveUserTextAnswers(ctx context.Context, questionId int32, userId int32) error {
+func (repo *SqlQuizRepository) RemoveUserTextAnswers(ctx context.Context, questionId int32, attemptId int32) error {
 	_, err := repo.DBProvider.ExecContext(
 		ctx,
 		`DELETE FROM text_answers WHERE
-		question_id = $1 AND user_id = $2`, questionId, userId)
+		question_id = $1 AND attempt_id = $2`, questionId, attemptId)
 	if err != nil {
 		return &apperrors.ErrInternal{Message: err.Error()}
 	}
@@ -509,12 +1065,74 @@ func (repo *SqlQuizRepository) RemoveUserTextAnswers(ctx context.Context, questi
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) AddUserTextAnswer(ctx context.Context, userId int32, questionId int32, text string) error {
+func (repo *SqlQuizRepository) AddUserTextAnswer(ctx context.Context, attemptId int32, questionId int32, text string) error {
 	var id int32
 	err := repo.DBProvider.QueryRowContext(
 		ctx,
-		"INSERT INTO text_answers (user_id, question_id, text_answer) VALUES ($1, $2, $3) RETURNING user_id",
-		userId, questionId, text).Scan(&id)
+		"INSERT INTO text_answers (attempt_id, question_id, text_answer) VALUES ($1, $2, $3) RETURNING attempt_id",
+		attemptId, questionId, text).Scan(&id)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddUserBlankAnswer(ctx context.Context, attemptId int32, questionId int32, blankNumber int32, text string) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		"INSERT INTO text_answers (attempt_id, question_id, blank_number, text_answer) VALUES ($1, $2, $3, $4)",
+		attemptId, questionId, blankNumber, text)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) RemoveUserOrderingAnswers(ctx context.Context, questionId int32, attemptId int32) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`DELETE FROM ordering_answers WHERE
+		question_id = $1 AND attempt_id = $2`, questionId, attemptId)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddUserOrderingAnswer(ctx context.Context, attemptId int32, questionId int32, itemId int32, position int32) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		"INSERT INTO ordering_answers (attempt_id, question_id, item_id, position) VALUES ($1, $2, $3, $4)",
+		attemptId, questionId, itemId, position)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) RemoveUserMatchingAnswers(ctx context.Context, questionId int32, attemptId int32) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`DELETE FROM matching_answers WHERE
+		question_id = $1 AND attempt_id = $2`, questionId, attemptId)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddUserMatchingAnswer(ctx context.Context, attemptId int32, questionId int32, pairId int32, matchedPairId int32) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		"INSERT INTO matching_answers (attempt_id, question_id, pair_id, matched_pair_id) VALUES ($1, $2, $3, $4)",
+		attemptId, questionId, pairId, matchedPairId)
 	if err != nil {
 		return &apperrors.ErrInternal{Message: err.Error()}
 	}
@@ -522,16 +1140,16 @@ func (repo *SqlQuizRepository) AddUserTextAnswer(ctx context.Context, userId int
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) UpsertUserScore(ctx context.Context, userId int32, quizId int32, score float32, time time.Time) error {
+func (repo *SqlQuizRepository) UpsertUserScore(ctx context.Context, userId int32, quizId int32, score float32, points float32, maxPoints float32, provisional bool, time time.Time) error {
 	res, err := repo.DBProvider.ExecContext(
 		ctx,
 		`INSERT INTO user_quiz_scores
-		(user_id, quiz_id, score, last_update_time)
-		VALUES ($1, $2, $3, $4)
+		(user_id, quiz_id, score, points, max_points, provisional, last_update_time)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		ON CONFLICT (user_id, quiz_id)
 		DO UPDATE SET
-		score = $3, last_update_time = $4`,
-		userId, quizId, score, time,
+		score = $3, points = $4, max_points = $5, provisional = $6, last_update_time = $7`,
+		userId, quizId, score, points, maxPoints, provisional, time,
 	)
 	if err != nil {
 		return &apperrors.ErrInternal{Message: err.Error()}
@@ -552,7 +1170,7 @@ func (repo *SqlQuizRepository) UpsertUserScore(ctx context.Context, userId int32
 func (repo *SqlQuizRepository) GetQuizStatistics(ctx context.Context, quizIds []int32) ([]*models.QuizStatistics, error) {
 	query :=
 		`SELECT
-		quiz_id, total_attempts, average_score, average_completion_time, last_update_time
+		quiz_id, total_attempts, average_score, pass_rate, average_completion_time, last_update_time
 		FROM quiz_statistics WHERE quiz_id = ANY($1::int[])`
 
 	rows, err := repo.DBProvider.QueryContext(
@@ -569,7 +1187,7 @@ func (repo *SqlQuizRepository) GetQuizStatistics(ctx context.Context, quizIds []
 	for rows.Next() {
 		var stats models.QuizStatistics
 		err = rows.Scan(
-			&stats.QuizId, &stats.TotalAttempts, &stats.AverageScore,
+			&stats.QuizId, &stats.TotalAttempts, &stats.AverageScore, &stats.PassRate,
 			&stats.AverageCompletionTime, &stats.LastUpdateTime)
 		if err != nil {
 			return nil, &apperrors.ErrInternal{Message: err.Error()}
@@ -587,10 +1205,11 @@ func (repo *SqlQuizRepository) GetUserScore(ctx context.Context, userId int32, q
 	err := repo.DBProvider.QueryRowContext(
 		ctx,
 		`SELECT
-		user_id, quiz_id, score, last_update_time
+		user_id, quiz_id, score, points, max_points, last_update_time, provisional
 		FROM user_quiz_scores WHERE user_id = $1 AND quiz_id = $2`,
 		userId, quizId).Scan(
-		&score.UserId, &score.QuizId, &score.Score, &score.LastUpdateTime)
+		&score.UserId, &score.QuizId, &score.Score,
+		&score.Points, &score.MaxPoints, &score.LastUpdateTime, &score.Provisional)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -603,13 +1222,47 @@ func (repo *SqlQuizRepository) GetUserScore(ctx context.Context, userId int32, q
 	return score, nil
 }
 
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetUserScores(ctx context.Context, userId int32, quizIds []int32) ([]*models.UserQuizScore, error) {
+	query :=
+		`SELECT
+		user_id, quiz_id, score, points, max_points, last_update_time, provisional
+		FROM user_quiz_scores WHERE user_id = $1 AND quiz_id = ANY($2::int[])`
+
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		query,
+		userId, pq.Array(quizIds),
+	)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allScores := make([]*models.UserQuizScore, 0)
+	for rows.Next() {
+		var score models.UserQuizScore
+		err = rows.Scan(
+			&score.UserId, &score.QuizId, &score.Score,
+			&score.Points, &score.MaxPoints, &score.LastUpdateTime, &score.Provisional)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allScores = append(allScores, &score)
+	}
+
+	return allScores, nil
+}
+
 // May return ErrInternal or ErrNotFound on failure.
 func (repo *SqlQuizRepository) GetQuizParticipationTime(ctx context.Context, userId int32, quizId int32) (*models.QuizParticipationTime, error) {
 	choice := &models.QuizParticipationTime{}
 	err := repo.DBProvider.QueryRowContext(
 		ctx,
 		`SELECT
-			user_id, quiz_id, started_at, finished_at, participation_number
+			id, user_id, quiz_id, quiz_version, started_at, finished_at, participation_number,
+			score, points, max_points, question_seed, choice_seed, passed
 		FROM
 			quiz_participation_times
 		WHERE
@@ -618,7 +1271,10 @@ func (repo *SqlQuizRepository) GetQuizParticipationTime(ctx context.Context, use
 			participation_number DESC
 		LIMIT 1`,
 		userId, quizId).Scan(
-		&choice.UserId, &choice.QuizId, &choice.StartedAt, &choice.FinishedAt, &choice.ParticipationNumber)
+		&choice.Id, &choice.UserId, &choice.QuizId, &choice.QuizVersion,
+		&choice.StartedAt, &choice.FinishedAt, &choice.ParticipationNumber,
+		&choice.Score, &choice.Points, &choice.MaxPoints,
+		&choice.QuestionSeed, &choice.ChoiceSeed, &choice.Passed)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -632,15 +1288,20 @@ func (repo *SqlQuizRepository) GetQuizParticipationTime(ctx context.Context, use
 }
 
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) GetUserTextAnswer(ctx context.Context, userId int32, questionId int32) (*models.TextAnswer, error) {
-	choice := &models.TextAnswer{}
+func (repo *SqlQuizRepository) GetParticipationTime(ctx context.Context, id int32) (*models.QuizParticipationTime, error) {
+	partTime := &models.QuizParticipationTime{}
 	err := repo.DBProvider.QueryRowContext(
 		ctx,
 		`SELECT
-		user_id, question_id, text_answer
-		FROM text_answers WHERE user_id = $1 AND question_id = $2`,
-		userId, questionId).Scan(
-		&choice.UserId, &choice.QuestionId, &choice.TextAnswer)
+			id, user_id, quiz_id, quiz_version, started_at, finished_at, participation_number,
+			score, points, max_points, question_seed, choice_seed, passed
+		FROM quiz_participation_times
+		WHERE id = $1`,
+		id).Scan(
+		&partTime.Id, &partTime.UserId, &partTime.QuizId, &partTime.QuizVersion,
+		&partTime.StartedAt, &partTime.FinishedAt, &partTime.ParticipationNumber,
+		&partTime.Score, &partTime.Points, &partTime.MaxPoints,
+		&partTime.QuestionSeed, &partTime.ChoiceSeed, &partTime.Passed)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -650,19 +1311,27 @@ func (repo *SqlQuizRepository) GetUserTextAnswer(ctx context.Context, userId int
 		}
 	}
 
-	return choice, nil
+	return partTime, nil
 }
 
+// Same as GetParticipationTime, but locks the attempt until the end of
+// the transaction.
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) GetUserChoiceAnswer(ctx context.Context, userId int32, questionId int32) (*models.ChoiceAnswer, error) {
-	choice := &models.ChoiceAnswer{}
+func (repo *SqlQuizRepository) LockParticipationTime(ctx context.Context, id int32) (*models.QuizParticipationTime, error) {
+	partTime := &models.QuizParticipationTime{}
 	err := repo.DBProvider.QueryRowContext(
 		ctx,
 		`SELECT
-		user_id, question_id, choice_id
-		FROM choice_answers WHERE user_id = $1 AND question_id = $2`,
-		userId, questionId).Scan(
-		&choice.UserId, &choice.QuestionId, &choice.ChoiceId)
+			id, user_id, quiz_id, quiz_version, started_at, finished_at, participation_number,
+			score, points, max_points, question_seed, choice_seed, passed
+		FROM quiz_participation_times
+		WHERE id = $1
+		FOR UPDATE`,
+		id).Scan(
+		&partTime.Id, &partTime.UserId, &partTime.QuizId, &partTime.QuizVersion,
+		&partTime.StartedAt, &partTime.FinishedAt, &partTime.ParticipationNumber,
+		&partTime.Score, &partTime.Points, &partTime.MaxPoints,
+		&partTime.QuestionSeed, &partTime.ChoiceSeed, &partTime.Passed)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -672,27 +1341,958 @@ func (repo *SqlQuizRepository) GetUserChoiceAnswer(ctx context.Context, userId i
 		}
 	}
 
-	return choice, nil
+	return partTime, nil
 }
 
+// Returns the attempts of the user on the quiz, the latest first.
 // May return ErrInternal or ErrNotFound on failure.
-func (repo *SqlQuizRepository) GetChoice(ctx context.Context, id int32) (*models.Choice, error) {
-	choice := &models.Choice{}
-	err := repo.DBProvider.QueryRowContext(
+func (repo *SqlQuizRepository) GetUserParticipationTimes(ctx context.Context, userId int32, quizId int32) ([]*models.QuizParticipationTime, error) {
+	rows, err := repo.DBProvider.QueryContext(
 		ctx,
 		`SELECT
-		id, question_id, choice_text, is_correct
-		FROM choices WHERE id = $1`,
-		id).Scan(
-		&choice.Id, &choice.QuestionId, &choice.ChoiceText, &choice.IsCorrect)
+			id, user_id, quiz_id, quiz_version, started_at, finished_at, participation_number,
+			score, points, max_points, question_seed, choice_seed, passed
+		FROM quiz_participation_times
+		WHERE user_id = $1 AND quiz_id = $2
+		ORDER BY participation_number DESC`,
+		userId, quizId)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	partTimes := make([]*models.QuizParticipationTime, 0)
+	for rows.Next() {
+		partTime := &models.QuizParticipationTime{}
+		err = rows.Scan(
+			&partTime.Id, &partTime.UserId, &partTime.QuizId, &partTime.QuizVersion,
+			&partTime.StartedAt, &partTime.FinishedAt, &partTime.ParticipationNumber,
+			&partTime.Score, &partTime.Points, &partTime.MaxPoints,
+			&partTime.QuestionSeed, &partTime.ChoiceSeed, &partTime.Passed)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+		partTimes = append(partTimes, partTime)
+	}
+
+	return partTimes, nil
+}
 
+// Returns the unfinished attempts of the quizzes closed before now - grace.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetClosedQuizAttempts(ctx context.Context, grace time.Duration, now time.Time) ([]*models.QuizParticipationTime, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT pt.id, pt.participation_number, pt.user_id, pt.quiz_id, pt.quiz_version, pt.started_at, pt.finished_at,
+		pt.score, pt.points, pt.max_points, pt.question_seed, pt.choice_seed, pt.passed
+		FROM quiz_participation_times pt
+		JOIN quizzes q ON q.id = pt.quiz_id
+		WHERE pt.finished_at IS NULL
+		AND q.closes_at + make_interval(secs => $1) < $2`,
+		grace.Seconds(), now)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, &apperrors.ErrNotFound{Message: "content not found"}
-		} else {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	partTimes := make([]*models.QuizParticipationTime, 0)
+	for rows.Next() {
+		partTime := &models.QuizParticipationTime{}
+		err = rows.Scan(
+			&partTime.Id, &partTime.ParticipationNumber, &partTime.UserId,
+			&partTime.QuizId, &partTime.QuizVersion, &partTime.StartedAt, &partTime.FinishedAt,
+			&partTime.Score, &partTime.Points, &partTime.MaxPoints,
+			&partTime.QuestionSeed, &partTime.ChoiceSeed, &partTime.Passed)
+		if err != nil {
 			return nil, &apperrors.ErrInternal{Message: err.Error()}
 		}
+		partTimes = append(partTimes, partTime)
 	}
 
-	return choice, nil
+	return partTimes, nil
+}
+
+// Publishes the drafts whose opening time has come and closes the published
+// quizzes whose closing time passed more than grace ago.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) UpdateScheduledQuizStatuses(ctx context.Context, grace time.Duration, now time.Time) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`UPDATE quizzes SET status = 'published', updated_at = $1
+		WHERE status = 'draft' AND opens_at <= $1
+		AND (closes_at IS NULL OR closes_at > $1)`,
+		now)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+
+	_, err = repo.DBProvider.ExecContext(
+		ctx,
+		`UPDATE quizzes SET status = 'closed', updated_at = $2
+		WHERE status = 'published'
+		AND closes_at + make_interval(secs => $1) < $2`,
+		grace.Seconds(), now)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Returns the finished attempts of the quiz that received the question.
+// Zero quiz id matches every quiz, zero question id every question.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetFinishedAttempts(ctx context.Context, quizId int32, questionId int32) ([]*models.QuizParticipationTime, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT
+			pt.id, pt.user_id, pt.quiz_id, pt.quiz_version, pt.started_at, pt.finished_at,
+			pt.participation_number, pt.score, pt.points, pt.max_points,
+			pt.question_seed, pt.choice_seed, pt.passed
+		FROM quiz_participation_times pt
+		WHERE pt.finished_at IS NOT NULL
+		AND ($1 = 0 OR pt.quiz_id = $1)
+		AND ($2 = 0
+			OR EXISTS (SELECT 1 FROM attempt_questions aq
+				WHERE aq.attempt_id = pt.id AND aq.question_id = $2)
+			-- Attempts without stored questions received every question of their quiz version
+			OR (NOT EXISTS (SELECT 1 FROM attempt_questions aq WHERE aq.attempt_id = pt.id)
+				AND EXISTS (SELECT 1 FROM questions q
+					WHERE q.id = $2 AND q.quiz_id = pt.quiz_id AND q.version = pt.quiz_version)))
+		ORDER BY pt.id`,
+		quizId, questionId)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	partTimes := make([]*models.QuizParticipationTime, 0)
+	for rows.Next() {
+		partTime := &models.QuizParticipationTime{}
+		err = rows.Scan(
+			&partTime.Id, &partTime.UserId, &partTime.QuizId, &partTime.QuizVersion,
+			&partTime.StartedAt, &partTime.FinishedAt, &partTime.ParticipationNumber,
+			&partTime.Score, &partTime.Points, &partTime.MaxPoints,
+			&partTime.QuestionSeed, &partTime.ChoiceSeed, &partTime.Passed)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+		partTimes = append(partTimes, partTime)
+	}
+
+	return partTimes, nil
+}
+
+// Returns true when an attempt of the quiz received the question.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) HasAttemptQuestion(ctx context.Context, quizId int32, questionId int32) (bool, error) {
+	var exists bool
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM attempt_questions aq
+			JOIN quiz_participation_times pt ON pt.id = aq.attempt_id
+			WHERE pt.quiz_id = $1 AND aq.question_id = $2)`,
+		quizId, questionId).Scan(&exists)
+	if err != nil {
+		return false, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return exists, nil
+}
+
+// Recalculates the statistics of the quiz from the user scores.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) CalculateQuizStatistics(ctx context.Context, quizId int32) error {
+	_, err := repo.DBProvider.ExecContext(ctx, "CALL calculate_quiz_statistics($1)", quizId)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetUserTextAnswer(ctx context.Context, attemptId int32, questionId int32) (*models.TextAnswer, error) {
+	choice := &models.TextAnswer{}
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`SELECT
+		attempt_id, question_id, text_answer
+		FROM text_answers WHERE attempt_id = $1 AND question_id = $2`,
+		attemptId, questionId).Scan(
+		&choice.AttemptId, &choice.QuestionId, &choice.TextAnswer)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &apperrors.ErrNotFound{Message: "content not found"}
+		} else {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	return choice, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetUserBlankAnswers(ctx context.Context, attemptId int32, questionId int32) ([]*models.TextAnswer, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT
+		attempt_id, question_id, blank_number, text_answer, answered_at
+		FROM text_answers WHERE attempt_id = $1 AND question_id = $2
+		ORDER BY blank_number`,
+		attemptId, questionId)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allAnswers := make([]*models.TextAnswer, 0)
+	for rows.Next() {
+		var answer models.TextAnswer
+		err = rows.Scan(
+			&answer.AttemptId, &answer.QuestionId, &answer.BlankNumber,
+			&answer.TextAnswer, &answer.AnsweredAt)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allAnswers = append(allAnswers, &answer)
+	}
+
+	return allAnswers, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetUserChoiceAnswers(ctx context.Context, attemptId int32, questionId int32) ([]*models.ChoiceAnswer, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT
+		attempt_id, question_id, choice_id, answered_at
+		FROM choice_answers WHERE attempt_id = $1 AND question_id = $2`,
+		attemptId, questionId)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allAnswers := make([]*models.ChoiceAnswer, 0)
+	for rows.Next() {
+		var answer models.ChoiceAnswer
+		err = rows.Scan(
+			&answer.AttemptId, &answer.QuestionId, &answer.ChoiceId, &answer.AnsweredAt)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allAnswers = append(allAnswers, &answer)
+	}
+
+	return allAnswers, nil
+}
+
+// Returns the answers in the order chosen by the user.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetUserOrderingAnswers(ctx context.Context, attemptId int32, questionId int32) ([]*models.OrderingAnswer, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT
+		attempt_id, question_id, item_id, position, answered_at
+		FROM ordering_answers WHERE attempt_id = $1 AND question_id = $2
+		ORDER BY position`,
+		attemptId, questionId)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allAnswers := make([]*models.OrderingAnswer, 0)
+	for rows.Next() {
+		var answer models.OrderingAnswer
+		err = rows.Scan(
+			&answer.AttemptId, &answer.QuestionId, &answer.ItemId,
+			&answer.Position, &answer.AnsweredAt)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allAnswers = append(allAnswers, &answer)
+	}
+
+	return allAnswers, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetUserMatchingAnswers(ctx context.Context, attemptId int32, questionId int32) ([]*models.MatchingAnswer, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT
+		attempt_id, question_id, pair_id, matched_pair_id, answered_at
+		FROM matching_answers WHERE attempt_id = $1 AND question_id = $2`,
+		attemptId, questionId)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allAnswers := make([]*models.MatchingAnswer, 0)
+	for rows.Next() {
+		var answer models.MatchingAnswer
+		err = rows.Scan(
+			&answer.AttemptId, &answer.QuestionId, &answer.PairId,
+			&answer.MatchedPairId, &answer.AnsweredAt)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allAnswers = append(allAnswers, &answer)
+	}
+
+	return allAnswers, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetChoice(ctx context.Context, id int32) (*models.Choice, error) {
+	choice := &models.Choice{}
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`SELECT
+		id, question_id, choice_text, is_correct
+		FROM choices WHERE id = $1`,
+		id).Scan(
+		&choice.Id, &choice.QuestionId, &choice.ChoiceText, &choice.IsCorrect)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &apperrors.ErrNotFound{Message: "content not found"}
+		} else {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	return choice, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddQuestionBank(ctx context.Context, bank *models.QuestionBank) (int32, error) {
+	var id int32
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`INSERT INTO question_banks (author_id, title, description, is_shared)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		bank.AuthorId, bank.Title, bank.Description, bank.IsShared).Scan(&id)
+	if err != nil {
+		return 0, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return id, nil
+}
+
+// Updates the bank and moves it to the next version.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) EditQuestionBank(ctx context.Context, id int32, title string, desc string, isShared bool) (int32, error) {
+	var version int32
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`UPDATE question_banks SET
+		title = $1, description = $2, is_shared = $3, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING version`,
+		title, desc, isShared, id).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, &apperrors.ErrNotFound{Message: "question bank not found"}
+		} else {
+			return 0, &apperrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return version, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetQuestionBank(ctx context.Context, id int32) (*models.QuestionBank, error) {
+	bank := &models.QuestionBank{}
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`SELECT id, author_id, title, description, is_shared, version, created_at, updated_at
+		FROM question_banks
+		WHERE id = $1`,
+		id).Scan(
+		&bank.Id, &bank.AuthorId, &bank.Title, &bank.Description, &bank.IsShared,
+		&bank.Version, &bank.CreatedAt, &bank.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &apperrors.ErrNotFound{Message: "content not found"}
+		} else {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	return bank, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetAllQuestionBanks(ctx context.Context) ([]*models.QuestionBank, error) {
+	query :=
+		`SELECT id, author_id, title, description, is_shared, version, created_at, updated_at
+		FROM question_banks
+		ORDER BY title`
+
+	return repo.queryQuestionBanks(ctx, query)
+}
+
+// Returns the banks of the user and the banks shared by other authors.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetUserQuestionBanks(ctx context.Context, userId int32) ([]*models.QuestionBank, error) {
+	query :=
+		`SELECT id, author_id, title, description, is_shared, version, created_at, updated_at
+		FROM question_banks
+		WHERE author_id = $1 OR is_shared
+		ORDER BY title`
+
+	return repo.queryQuestionBanks(ctx, query, userId)
+}
+
+// Returns how many questions the current versions of the banks have
+// and their tags in alphabetical order.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetQuestionBankContents(ctx context.Context, bankIds []int32) ([]*models.QuestionBankContents, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT b.id, COUNT(q.id),
+		COALESCE(array_agg(DISTINCT q.tag) FILTER (WHERE q.tag IS NOT NULL AND q.tag <> ''), '{}')
+		FROM question_banks b
+		LEFT JOIN questions q ON q.bank_id = b.id AND q.version = b.version
+		WHERE b.id = ANY($1)
+		GROUP BY b.id`,
+		pq.Array(bankIds))
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allContents := make([]*models.QuestionBankContents, 0)
+	for rows.Next() {
+		var contents models.QuestionBankContents
+		err = rows.Scan(&contents.BankId, &contents.QuestionCount, pq.Array(&contents.Tags))
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allContents = append(allContents, &contents)
+	}
+
+	return allContents, nil
+}
+
+func (repo *SqlQuizRepository) queryQuestionBanks(ctx context.Context, query string, args ...any) ([]*models.QuestionBank, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		query,
+		args...,
+	)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allBanks := make([]*models.QuestionBank, 0)
+	for rows.Next() {
+		var bank models.QuestionBank
+		err = rows.Scan(
+			&bank.Id, &bank.AuthorId, &bank.Title, &bank.Description, &bank.IsShared,
+			&bank.Version, &bank.CreatedAt, &bank.UpdatedAt)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allBanks = append(allBanks, &bank)
+	}
+
+	return allBanks, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddDrawRule(ctx context.Context, rule *models.QuizDrawRule) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`INSERT INTO quiz_draw_rules (quiz_id, version, bank_id, tag, category_id, question_count, position)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, 0), $6, $7)`,
+		rule.QuizId, rule.Version, rule.BankId, rule.Tag, rule.CategoryId, rule.QuestionCount, rule.Position)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Returns the draw rules of the quiz version in their order.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetDrawRules(ctx context.Context, quizId int32, version int32) ([]*models.QuizDrawRule, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT id, quiz_id, version, bank_id, COALESCE(tag, ''), COALESCE(category_id, 0), question_count, position
+		FROM quiz_draw_rules
+		WHERE quiz_id = $1 AND version = $2
+		ORDER BY position`,
+		quizId, version)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allRules := make([]*models.QuizDrawRule, 0)
+	for rows.Next() {
+		var rule models.QuizDrawRule
+		err = rows.Scan(
+			&rule.Id, &rule.QuizId, &rule.Version, &rule.BankId,
+			&rule.Tag, &rule.CategoryId, &rule.QuestionCount, &rule.Position)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allRules = append(allRules, &rule)
+	}
+
+	return allRules, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddQuestionVariable(ctx context.Context, variable *models.QuestionVariable) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`INSERT INTO question_variables
+		(question_id, name, min_value, max_value, step)
+		VALUES ($1, $2, $3, $4, $5)`,
+		variable.QuestionId, variable.Name, variable.MinValue,
+		variable.MaxValue, variable.Step)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Returns the variables of the questions ordered by name.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetQuestionVariables(ctx context.Context, questionIds []int32) ([]*models.QuestionVariable, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT id, question_id, name, min_value, max_value, step
+		FROM question_variables
+		WHERE question_id = ANY($1)
+		ORDER BY question_id, name`,
+		pq.Array(questionIds))
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allVariables := make([]*models.QuestionVariable, 0)
+	for rows.Next() {
+		var variable models.QuestionVariable
+		err = rows.Scan(
+			&variable.Id, &variable.QuestionId, &variable.Name,
+			&variable.MinValue, &variable.MaxValue, &variable.Step)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allVariables = append(allVariables, &variable)
+	}
+
+	return allVariables, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddAttemptVariables(ctx context.Context, variables []*models.AttemptVariable) error {
+	if len(variables) == 0 {
+		return nil
+	}
+	attemptIds := make([]int32, len(variables))
+	questionIds := make([]int32, len(variables))
+	names := make([]string, len(variables))
+	values := make([]float64, len(variables))
+	for i, v := range variables {
+		attemptIds[i] = v.AttemptId
+		questionIds[i] = v.QuestionId
+		names[i] = v.Name
+		values[i] = v.Value
+	}
+
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`INSERT INTO attempt_variables (attempt_id, question_id, name, value)
+		SELECT * FROM unnest($1::int[], $2::int[], $3::varchar[], $4::float8[])`,
+		pq.Array(attemptIds), pq.Array(questionIds), pq.Array(names), pq.Array(values))
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Returns the values generated for the question in the attempt.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetAttemptVariables(ctx context.Context, attemptId int32, questionId int32) ([]*models.AttemptVariable, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT attempt_id, question_id, name, value
+		FROM attempt_variables
+		WHERE attempt_id = $1 AND question_id = $2`,
+		attemptId, questionId)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allVariables := make([]*models.AttemptVariable, 0)
+	for rows.Next() {
+		var variable models.AttemptVariable
+		err = rows.Scan(&variable.AttemptId, &variable.QuestionId, &variable.Name, &variable.Value)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allVariables = append(allVariables, &variable)
+	}
+
+	return allVariables, nil
+}
+
+// Tokens already stored for the question in the attempt are kept.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddAttemptItemTokens(ctx context.Context, tokens []*models.AttemptItemToken) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	attemptIds := make([]int32, len(tokens))
+	questionIds := make([]int32, len(tokens))
+	itemIds := make([]int32, len(tokens))
+	values := make([]int32, len(tokens))
+	for i, t := range tokens {
+		attemptIds[i] = t.AttemptId
+		questionIds[i] = t.QuestionId
+		itemIds[i] = t.ItemId
+		values[i] = t.Token
+	}
+
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`INSERT INTO attempt_item_tokens (attempt_id, question_id, item_id, token)
+		SELECT * FROM unnest($1::int[], $2::int[], $3::int[], $4::int[])
+		ON CONFLICT DO NOTHING`,
+		pq.Array(attemptIds), pq.Array(questionIds), pq.Array(itemIds), pq.Array(values))
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Returns the tokens standing in for the items of the question in the attempt.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetAttemptItemTokens(ctx context.Context, attemptId int32, questionId int32) ([]*models.AttemptItemToken, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT attempt_id, question_id, item_id, token
+		FROM attempt_item_tokens
+		WHERE attempt_id = $1 AND question_id = $2
+		ORDER BY token`,
+		attemptId, questionId)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allTokens := make([]*models.AttemptItemToken, 0)
+	for rows.Next() {
+		var token models.AttemptItemToken
+		err = rows.Scan(&token.AttemptId, &token.QuestionId, &token.ItemId, &token.Token)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allTokens = append(allTokens, &token)
+	}
+
+	return allTokens, nil
+}
+
+// Puts the answer into the manual grading queue unless it is there already.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddManualGrade(ctx context.Context, attemptId int32, questionId int32) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`INSERT INTO manual_grades (attempt_id, question_id)
+		VALUES ($1, $2)
+		ON CONFLICT (attempt_id, question_id) DO NOTHING`,
+		attemptId, questionId)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetManualGrade(ctx context.Context, attemptId int32, questionId int32) (*models.ManualGrade, error) {
+	grade := &models.ManualGrade{}
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`SELECT attempt_id, question_id, points, feedback, reviewer_id, graded_at
+		FROM manual_grades
+		WHERE attempt_id = $1 AND question_id = $2`,
+		attemptId, questionId).Scan(
+		&grade.AttemptId, &grade.QuestionId, &grade.Points,
+		&grade.Feedback, &grade.ReviewerId, &grade.GradedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &apperrors.ErrNotFound{Message: "content not found"}
+		} else {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return grade, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) UpdateManualGrade(ctx context.Context, grade *models.ManualGrade) error {
+	res, err := repo.DBProvider.ExecContext(
+		ctx,
+		`UPDATE manual_grades
+		SET points = $3, feedback = NULLIF($4, ''), reviewer_id = $5, graded_at = $6
+		WHERE attempt_id = $1 AND question_id = $2`,
+		grade.AttemptId, grade.QuestionId, grade.Points,
+		grade.Feedback, grade.ReviewerId, grade.GradedAt)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	if rowsAffected < 1 {
+		return &apperrors.ErrNotFound{Message: "content not found"}
+	}
+	return nil
+}
+
+// Returns the ungraded answers of finished attempts, oldest first.
+// Zero author id returns the answers to the quizzes of all authors.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetPendingReviews(ctx context.Context, authorId int32) ([]*models.PendingReview, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT
+		mg.attempt_id, mg.question_id, q.id, q.title, qu.question_text, qu.points,
+		u.username, ta.text_answer, pt.finished_at
+		FROM manual_grades mg
+		JOIN quiz_participation_times pt ON pt.id = mg.attempt_id
+		JOIN quizzes q ON q.id = pt.quiz_id
+		JOIN questions qu ON qu.id = mg.question_id
+		JOIN users u ON u.id = pt.user_id
+		LEFT JOIN text_answers ta
+		ON ta.attempt_id = mg.attempt_id AND ta.question_id = mg.question_id AND ta.blank_number = 0
+		WHERE mg.points IS NULL AND pt.finished_at IS NOT NULL
+		AND ($1 = 0 OR q.author_id = $1)
+		ORDER BY pt.finished_at, mg.attempt_id, mg.question_id`,
+		authorId)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	allReviews := make([]*models.PendingReview, 0)
+	for rows.Next() {
+		var review models.PendingReview
+		err = rows.Scan(
+			&review.AttemptId, &review.QuestionId, &review.QuizId, &review.QuizTitle,
+			&review.QuestionText, &review.MaxPoints, &review.Username,
+			&review.TextAnswer, &review.FinishedAt)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+
+		allReviews = append(allReviews, &review)
+	}
+
+	return allReviews, nil
+}
+
+// Returns how many answers of the attempts wait for grading.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) CountPendingReviews(ctx context.Context, attemptIds []int32) (int, error) {
+	var count int
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*)
+		FROM manual_grades
+		WHERE attempt_id = ANY($1) AND points IS NULL`,
+		pq.Array(attemptIds)).Scan(&count)
+	if err != nil {
+		return 0, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return count, nil
+}
+
+// Does nothing when the user already has a certificate for the quiz.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddCertificate(ctx context.Context, certificate *models.Certificate) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		`INSERT INTO certificates (code, user_id, quiz_id, attempt_id, username, quiz_title, score, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, quiz_id) DO NOTHING`,
+		certificate.Code, certificate.UserId, certificate.QuizId, certificate.AttemptId,
+		certificate.Username, certificate.QuizTitle, certificate.Score, certificate.IssuedAt)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetCertificate(ctx context.Context, code string) (*models.Certificate, error) {
+	certificate := &models.Certificate{}
+	err := repo.DBProvider.QueryRowContext(
+		ctx,
+		`SELECT id, code, user_id, quiz_id, attempt_id, username, quiz_title, score, issued_at
+		FROM certificates
+		WHERE code = $1`,
+		code).Scan(
+		&certificate.Id, &certificate.Code, &certificate.UserId, &certificate.QuizId,
+		&certificate.AttemptId, &certificate.Username, &certificate.QuizTitle,
+		&certificate.Score, &certificate.IssuedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &apperrors.ErrNotFound{Message: "content not found"}
+		} else {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return certificate, nil
+}
+
+// Returns the certificates of the user, newest first.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetUserCertificates(ctx context.Context, userId int32) ([]*models.Certificate, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT id, code, user_id, quiz_id, attempt_id, username, quiz_title, score, issued_at
+		FROM certificates
+		WHERE user_id = $1
+		ORDER BY issued_at DESC, id DESC`,
+		userId)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	certificates := make([]*models.Certificate, 0)
+	for rows.Next() {
+		certificate := &models.Certificate{}
+		err = rows.Scan(
+			&certificate.Id, &certificate.Code, &certificate.UserId, &certificate.QuizId,
+			&certificate.AttemptId, &certificate.Username, &certificate.QuizTitle,
+			&certificate.Score, &certificate.IssuedAt)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
+
+// Removes the certificate issued for the attempt, returns false when
+// the attempt has none.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) RemoveAttemptCertificate(ctx context.Context, attemptId int32) (bool, error) {
+	res, err := repo.DBProvider.ExecContext(
+		ctx,
+		`DELETE FROM certificates WHERE attempt_id = $1`,
+		attemptId)
+	if err != nil {
+		return false, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return rowsAffected > 0, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddQuestionExplanation(ctx context.Context, questionId int32, explanation string) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		"INSERT INTO question_explanations (question_id, explanation) VALUES ($1, $2)",
+		questionId, explanation)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetQuestionExplanations(ctx context.Context, questionIds []int32) ([]*models.QuestionExplanation, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT question_id, explanation
+		FROM question_explanations
+		WHERE question_id = ANY($1::int[])`,
+		pq.Array(questionIds))
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	explanations := make([]*models.QuestionExplanation, 0)
+	for rows.Next() {
+		explanation := &models.QuestionExplanation{}
+		err = rows.Scan(&explanation.QuestionId, &explanation.Explanation)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+		explanations = append(explanations, explanation)
+	}
+
+	return explanations, nil
+}
+
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) AddChoiceFeedback(ctx context.Context, choiceId int32, feedback string) error {
+	_, err := repo.DBProvider.ExecContext(
+		ctx,
+		"INSERT INTO choice_feedback (choice_id, feedback) VALUES ($1, $2)",
+		choiceId, feedback)
+	if err != nil {
+		return &apperrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Returns the feedback of the choices of the question.
+// May return ErrInternal or ErrNotFound on failure.
+func (repo *SqlQuizRepository) GetChoiceFeedback(ctx context.Context, questionId int32) ([]*models.ChoiceFeedback, error) {
+	rows, err := repo.DBProvider.QueryContext(
+		ctx,
+		`SELECT cf.choice_id, cf.feedback
+		FROM choice_feedback cf
+		JOIN choices c ON c.id = cf.choice_id
+		WHERE c.question_id = $1`,
+		questionId)
+	if err != nil {
+		return nil, &apperrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	feedback := make([]*models.ChoiceFeedback, 0)
+	for rows.Next() {
+		v := &models.ChoiceFeedback{}
+		err = rows.Scan(&v.ChoiceId, &v.Feedback)
+		if err != nil {
+			return nil, &apperrors.ErrInternal{Message: err.Error()}
+		}
+		feedback = append(feedback, v)
+	}
+
+	return feedback, nil
 }
@@ -26,6 +26,12 @@ type (
 
 	App struct {
 		Port int `json:"port"`
+		// Extra time accepted after the time limit of an attempt.
+		AttemptGraceSeconds int `json:"attempt_grace_seconds"`
+		// How often abandoned attempts are finalized.
+		AttemptSweepSeconds int `json:"attempt_sweep_seconds"`
+		// How often quizzes are opened and closed by their schedule.
+		QuizScheduleSeconds int `json:"quiz_schedule_seconds"`
 	}
 
 	Database struct {
@@ -50,8 +50,31 @@ type Quiz struct {
 	AuthorId    *int32    `json:"author_id" db:"author_id"`
 	Title       string    `json:"title" db:"title"`
 	Description *string   `json:"description" db:"description"`
+	Version     int32     `json:"version" db:"version"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	TimeLimitSeconds *int32 `json:"time_limit_seconds" db:"time_limit_seconds"`
+	MaxAttempts      *int32 `json:"max_attempts" db:"max_attempts"`
+	CooldownSeconds  *int32 `json:"cooldown_seconds" db:"cooldown_seconds"`
+	ScorePolicy      string `json:"score_policy" db:"score_policy"`
+	ShuffleQuestions bool   `json:"shuffle_questions" db:"shuffle_questions"`
+	ShuffleChoices   bool   `json:"shuffle_choices" db:"shuffle_choices"`
+
+	Status   string     `json:"status" db:"status"`
+	OpensAt  *time.Time `json:"opens_at" db:"opens_at"`
+	ClosesAt *time.Time `json:"closes_at" db:"closes_at"`
+
+	RevealPolicy string   `json:"reveal_policy" db:"reveal_policy"`
+	PassMark     *float64 `json:"pass_mark" db:"pass_mark"`
+}
+
+type QuizVersion struct {
+	QuizId      int32     `json:"quiz_id" db:"quiz_id"`
+	Version     int32     `json:"version" db:"version"`
+	Title       string    `json:"title" db:"title"`
+	Description *string   `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
 type QuizCategory struct {
@@ -59,16 +82,88 @@ type QuizCategory struct {
 	CategoryId int32 `json:"category_id" db:"category_id"`
 }
 
+type QuestionBank struct {
+	Id          int32     `json:"id" db:"id"`
+	AuthorId    *int32    `json:"author_id" db:"author_id"`
+	Title       string    `json:"title" db:"title"`
+	Description *string   `json:"description" db:"description"`
+	IsShared    bool      `json:"is_shared" db:"is_shared"`
+	Version     int32     `json:"version" db:"version"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Questions of the current version of a bank.
+type QuestionBankContents struct {
+	BankId        int32    `json:"bank_id" db:"bank_id"`
+	QuestionCount int      `json:"question_count" db:"question_count"`
+	Tags          []string `json:"tags" db:"tags"`
+}
+
 type Question struct {
-	Id           int32  `json:"id" db:"id"`
-	QuizId       int32  `json:"quiz_id" db:"quiz_id"`
-	QuestionText string `json:"question_text" db:"question_text"`
-	QuestionType string `json:"question_type" db:"question_type"`
+	Id             int32   `json:"id" db:"id"`
+	QuizId         int32   `json:"quiz_id" db:"quiz_id"`
+	BankId         int32   `json:"bank_id" db:"bank_id"`
+	Version        int32   `json:"version" db:"version"`
+	Tag            string  `json:"tag" db:"tag"`
+	CategoryId     int32   `json:"category_id" db:"category_id"`
+	QuestionText   string  `json:"question_text" db:"question_text"`
+	QuestionType   string  `json:"question_type" db:"question_type"`
+	ScoringMode    string  `json:"scoring_mode" db:"scoring_mode"`
+	Points         float64 `json:"points" db:"points"`
+	NegativePoints float64 `json:"negative_points" db:"negative_points"`
+	AnswerFormula  string  `json:"answer_formula" db:"answer_formula"`
+}
+
+type QuestionVariable struct {
+	Id         int32   `json:"id" db:"id"`
+	QuestionId int32   `json:"question_id" db:"question_id"`
+	Name       string  `json:"name" db:"name"`
+	MinValue   float64 `json:"min_value" db:"min_value"`
+	MaxValue   float64 `json:"max_value" db:"max_value"`
+	Step       float64 `json:"step" db:"step"`
+}
+
+type AttemptVariable struct {
+	AttemptId  int32   `json:"attempt_id" db:"attempt_id"`
+	QuestionId int32   `json:"question_id" db:"question_id"`
+	Name       string  `json:"name" db:"name"`
+	Value      float64 `json:"value" db:"value"`
+}
+
+type AttemptItemToken struct {
+	AttemptId  int32 `json:"attempt_id" db:"attempt_id"`
+	QuestionId int32 `json:"question_id" db:"question_id"`
+	ItemId     int32 `json:"item_id" db:"item_id"`
+	Token      int32 `json:"token" db:"token"`
+}
+
+type QuizDrawRule struct {
+	Id            int32  `json:"id" db:"id"`
+	QuizId        int32  `json:"quiz_id" db:"quiz_id"`
+	Version       int32  `json:"version" db:"version"`
+	BankId        int32  `json:"bank_id" db:"bank_id"`
+	Tag           string `json:"tag" db:"tag"`
+	CategoryId    int32  `json:"category_id" db:"category_id"`
+	QuestionCount int32  `json:"question_count" db:"question_count"`
+	Position      int32  `json:"position" db:"position"`
 }
 
 type TextQuestionAnswer struct {
+	Id          int32  `json:"id" db:"id"`
 	QuestionId  int32  `json:"question_id" db:"question_id"`
+	BlankNumber int32  `json:"blank_number" db:"blank_number"`
 	RightAnswer string `json:"right_answer" db:"right_answer"`
+	MatchMode   string `json:"match_mode" db:"match_mode"`
+	MaxDistance int32  `json:"max_distance" db:"max_distance"`
+}
+
+type NumericQuestionAnswer struct {
+	QuestionId    int32    `json:"question_id" db:"question_id"`
+	TargetValue   float64  `json:"target_value" db:"target_value"`
+	Tolerance     float64  `json:"tolerance" db:"tolerance"`
+	ToleranceMode string   `json:"tolerance_mode" db:"tolerance_mode"`
+	Units         []string `json:"units" db:"units"`
 }
 
 type Choice struct {
@@ -78,25 +173,75 @@ type Choice struct {
 	IsCorrect  bool   `json:"is_correct" db:"is_correct"`
 }
 
+type QuestionExplanation struct {
+	QuestionId  int32  `json:"question_id" db:"question_id"`
+	Explanation string `json:"explanation" db:"explanation"`
+}
+
+type ChoiceFeedback struct {
+	ChoiceId int32  `json:"choice_id" db:"choice_id"`
+	Feedback string `json:"feedback" db:"feedback"`
+}
+
+type ClozeBlankOption struct {
+	Id          int32  `json:"id" db:"id"`
+	QuestionId  int32  `json:"question_id" db:"question_id"`
+	BlankNumber int32  `json:"blank_number" db:"blank_number"`
+	OptionText  string `json:"option_text" db:"option_text"`
+	IsCorrect   bool   `json:"is_correct" db:"is_correct"`
+}
+
+type OrderingItem struct {
+	Id         int32  `json:"id" db:"id"`
+	QuestionId int32  `json:"question_id" db:"question_id"`
+	ItemText   string `json:"item_text" db:"item_text"`
+	Position   int32  `json:"position" db:"position"`
+}
+
+type MatchingPair struct {
+	Id         int32  `json:"id" db:"id"`
+	QuestionId int32  `json:"question_id" db:"question_id"`
+	LeftText   string `json:"left_text" db:"left_text"`
+	RightText  string `json:"right_text" db:"right_text"`
+	Position   int32  `json:"position" db:"position"`
+}
+
 type ChoiceQuestionAnswer struct {
 	QuestionId    int32 `json:"question_id" db:"question_id"`
 	RightChoiceId int32 `json:"right_choice_id" db:"right_choice_id"`
 }
 
 type ChoiceAnswer struct {
-	UserId     int32     `json:"user_id" db:"user_id"`
+	AttemptId  int32     `json:"attempt_id" db:"attempt_id"`
 	QuestionId int32     `json:"question_id" db:"question_id"`
 	ChoiceId   *int32    `json:"choice_id" db:"choice_id"`
 	AnsweredAt time.Time `json:"answered_at" db:"answered_at"`
 }
 
 type TextAnswer struct {
-	UserId     int32     `json:"user_id" db:"user_id"`
+	AttemptId   int32     `json:"attempt_id" db:"attempt_id"`
+	QuestionId  int32     `json:"question_id" db:"question_id"`
+	BlankNumber int32     `json:"blank_number" db:"blank_number"`
+	TextAnswer  *string   `json:"text_answer" db:"text_answer"`
+	AnsweredAt  time.Time `json:"answered_at" db:"answered_at"`
+}
+
+type OrderingAnswer struct {
+	AttemptId  int32     `json:"attempt_id" db:"attempt_id"`
 	QuestionId int32     `json:"question_id" db:"question_id"`
-	TextAnswer *string   `json:"text_answer" db:"text_answer"`
+	ItemId     int32     `json:"item_id" db:"item_id"`
+	Position   int32     `json:"position" db:"position"`
 	AnsweredAt time.Time `json:"answered_at" db:"answered_at"`
 }
 
+type MatchingAnswer struct {
+	AttemptId     int32     `json:"attempt_id" db:"attempt_id"`
+	QuestionId    int32     `json:"question_id" db:"question_id"`
+	PairId        int32     `json:"pair_id" db:"pair_id"`
+	MatchedPairId int32     `json:"matched_pair_id" db:"matched_pair_id"`
+	AnsweredAt    time.Time `json:"answered_at" db:"answered_at"`
+}
+
 type UserQuizParticipation struct {
 	UserId             int32 `json:"user_id" db:"user_id"`
 	QuizId             int32 `json:"quiz_id" db:"quiz_id"`
@@ -107,7 +252,45 @@ type UserQuizScore struct {
 	UserId         int32     `json:"user_id" db:"user_id"`
 	QuizId         int32     `json:"quiz_id" db:"quiz_id"`
 	Score          float64   `json:"score" db:"score"`
+	Points         float64   `json:"points" db:"points"`
+	MaxPoints      float64   `json:"max_points" db:"max_points"`
 	LastUpdateTime time.Time `json:"last_update_time" db:"last_update_time"`
+	Provisional    bool      `json:"provisional" db:"provisional"`
+}
+
+type ManualGrade struct {
+	AttemptId  int32      `json:"attempt_id" db:"attempt_id"`
+	QuestionId int32      `json:"question_id" db:"question_id"`
+	Points     *float64   `json:"points" db:"points"`
+	Feedback   *string    `json:"feedback" db:"feedback"`
+	ReviewerId *int32     `json:"reviewer_id" db:"reviewer_id"`
+	GradedAt   *time.Time `json:"graded_at" db:"graded_at"`
+}
+
+// Answer waiting in the manual grading queue.
+type PendingReview struct {
+	AttemptId    int32     `json:"attempt_id" db:"attempt_id"`
+	QuestionId   int32     `json:"question_id" db:"question_id"`
+	QuizId       int32     `json:"quiz_id" db:"quiz_id"`
+	QuizTitle    string    `json:"quiz_title" db:"quiz_title"`
+	QuestionText string    `json:"question_text" db:"question_text"`
+	MaxPoints    float64   `json:"max_points" db:"max_points"`
+	Username     string    `json:"username" db:"username"`
+	TextAnswer   *string   `json:"text_answer" db:"text_answer"`
+	FinishedAt   time.Time `json:"finished_at" db:"finished_at"`
+}
+
+// Certificate issued for passing a quiz, keeps the names it was issued with.
+type Certificate struct {
+	Id        int32     `json:"id" db:"id"`
+	Code      string    `json:"code" db:"code"`
+	UserId    int32     `json:"user_id" db:"user_id"`
+	QuizId    int32     `json:"quiz_id" db:"quiz_id"`
+	AttemptId int32     `json:"attempt_id" db:"attempt_id"`
+	Username  string    `json:"username" db:"username"`
+	QuizTitle string    `json:"quiz_title" db:"quiz_title"`
+	Score     float64   `json:"score" db:"score"`
+	IssuedAt  time.Time `json:"issued_at" db:"issued_at"`
 }
 
 type QuizParticipationTime struct {
@@ -115,14 +298,22 @@ type QuizParticipationTime struct {
 	ParticipationNumber int        `json:"participation_number" db:"participation_number"`
 	UserId              int32      `json:"user_id" db:"user_id"`
 	QuizId              int32      `json:"quiz_id" db:"quiz_id"`
+	QuizVersion         int32      `json:"quiz_version" db:"quiz_version"`
 	StartedAt           time.Time  `json:"started_at" db:"started_at"`
 	FinishedAt          *time.Time `json:"finished_at" db:"finished_at"`
+	Score               *float64   `json:"score" db:"score"`
+	Points              *float64   `json:"points" db:"points"`
+	MaxPoints           *float64   `json:"max_points" db:"max_points"`
+	QuestionSeed        *int64     `json:"question_seed" db:"question_seed"`
+	ChoiceSeed          *int64     `json:"choice_seed" db:"choice_seed"`
+	Passed              *bool      `json:"passed" db:"passed"`
 }
 
 type QuizStatistics struct {
 	QuizId                int32     `json:"quiz_id" db:"quiz_id"`
 	TotalAttempts         int       `json:"total_attempts" db:"total_attempts"`
 	AverageScore          float64   `json:"average_score" db:"average_score"`
+	PassRate              *float64  `json:"pass_rate" db:"pass_rate"`
 	AverageCompletionTime *string   `json:"average_completion_time" db:"average_completion_time"`
 	LastUpdateTime        time.Time `json:"last_update_time" db:"last_update_time"`
 }
@@ -0,0 +1,276 @@
+package utility
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Deepest nesting of parentheses, unary signs and powers a formula may have.
+const MAX_FORMULA_DEPTH = 100
+
+var formulaFunctions = map[string]func(args []float64) (float64, error){
+	"abs":   unaryFunction(math.Abs),
+	"sqrt":  unaryFunction(math.Sqrt),
+	"round": unaryFunction(math.Round),
+	"floor": unaryFunction(math.Floor),
+	"ceil":  unaryFunction(math.Ceil),
+	"ln":    unaryFunction(math.Log),
+	"log10": unaryFunction(math.Log10),
+	"sin":   unaryFunction(math.Sin),
+	"cos":   unaryFunction(math.Cos),
+	"tan":   unaryFunction(math.Tan),
+	"min":   variadicFunction(math.Min),
+	"max":   variadicFunction(math.Max),
+}
+
+func unaryFunction(f func(float64) float64) func(args []float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		return f(args[0]), nil
+	}
+}
+
+func variadicFunction(f func(float64, float64) float64) func(args []float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, fmt.Errorf("expected at least 1 argument")
+		}
+		res := args[0]
+		for _, a := range args[1:] {
+			res = f(res, a)
+		}
+		return res, nil
+	}
+}
+
+// Evaluates an arithmetic formula such as "sqrt(a^2 + b^2) / 2" with the
+// given variables. Supports + - * / % ^, parentheses, unary minus, numbers
+// in exponent form like 1.5e-3, the constants pi and e and a few math
+// functions.
+func EvalFormula(formula string, vars map[string]float64) (float64, error) {
+	p := &formulaParser{text: formula, vars: vars}
+	res, err := p.parseSum()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.text) {
+		return 0, fmt.Errorf("unexpected %q at %d", p.text[p.pos], p.pos)
+	}
+	if math.IsNaN(res) || math.IsInf(res, 0) {
+		return 0, fmt.Errorf("formula has no finite value")
+	}
+	return res, nil
+}
+
+func isFormulaIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type formulaParser struct {
+	text  string
+	pos   int
+	vars  map[string]float64
+	depth int
+}
+
+func (p *formulaParser) skipSpaces() {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// Consumes the next character when it is one of the given ones.
+func (p *formulaParser) accept(chars string) (byte, bool) {
+	p.skipSpaces()
+	if p.pos < len(p.text) && strings.IndexByte(chars, p.text[p.pos]) >= 0 {
+		p.pos++
+		return p.text[p.pos-1], true
+	}
+	return 0, false
+}
+
+// Consumes the exponent part of a number literal such as "e-3". A lone
+// "e" is left alone, since it may be the constant e.
+func (p *formulaParser) skipExponent() {
+	i := p.pos
+	if i >= len(p.text) || (p.text[i] != 'e' && p.text[i] != 'E') {
+		return
+	}
+	i++
+	if i < len(p.text) && (p.text[i] == '+' || p.text[i] == '-') {
+		i++
+	}
+	if i >= len(p.text) || !unicode.IsDigit(rune(p.text[i])) {
+		return
+	}
+	for i < len(p.text) && unicode.IsDigit(rune(p.text[i])) {
+		i++
+	}
+	p.pos = i
+}
+
+func (p *formulaParser) parseSum() (float64, error) {
+	res, err := p.parseProduct()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op, ok := p.accept("+-")
+		if !ok {
+			return res, nil
+		}
+		rhs, err := p.parseProduct()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			res += rhs
+		} else {
+			res -= rhs
+		}
+	}
+}
+
+func (p *formulaParser) parseProduct() (float64, error) {
+	res, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op, ok := p.accept("*/%")
+		if !ok {
+			return res, nil
+		}
+		rhs, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			res *= rhs
+		case '/':
+			if rhs == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			res /= rhs
+		case '%':
+			if rhs == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			res = math.Mod(res, rhs)
+		}
+	}
+}
+
+// Every nested subformula passes through here, so this is where the
+// nesting depth is limited.
+func (p *formulaParser) parseUnary() (float64, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > MAX_FORMULA_DEPTH {
+		return 0, fmt.Errorf("formula is nested deeper than %d levels", MAX_FORMULA_DEPTH)
+	}
+
+	if op, ok := p.accept("+-"); ok {
+		res, err := p.parseUnary()
+		if op == '-' {
+			res = -res
+		}
+		return res, err
+	}
+	return p.parsePower()
+}
+
+// Power binds tighter than unary minus on its left and is right associative.
+func (p *formulaParser) parsePower() (float64, error) {
+	base, err := p.parseAtom()
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := p.accept("^"); ok {
+		exp, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		return math.Pow(base, exp), nil
+	}
+	return base, nil
+}
+
+func (p *formulaParser) parseAtom() (float64, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.text) {
+		return 0, fmt.Errorf("unexpected end of formula")
+	}
+
+	if _, ok := p.accept("("); ok {
+		res, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return 0, fmt.Errorf("missing closing parenthesis")
+		}
+		return res, nil
+	}
+
+	start := p.pos
+	c := rune(p.text[p.pos])
+	if unicode.IsDigit(c) || c == '.' {
+		for p.pos < len(p.text) && (unicode.IsDigit(rune(p.text[p.pos])) || p.text[p.pos] == '.') {
+			p.pos++
+		}
+		p.skipExponent()
+		res, err := strconv.ParseFloat(p.text[start:p.pos], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", p.text[start:p.pos])
+		}
+		return res, nil
+	}
+
+	if !unicode.IsLetter(c) && c != '_' {
+		return 0, fmt.Errorf("unexpected %q at %d", c, p.pos)
+	}
+	for p.pos < len(p.text) && isFormulaIdentRune(rune(p.text[p.pos])) {
+		p.pos++
+	}
+	name := p.text[start:p.pos]
+
+	if f, ok := formulaFunctions[name]; ok {
+		if _, ok := p.accept("("); !ok {
+			return 0, fmt.Errorf("missing arguments of %s", name)
+		}
+		args := make([]float64, 0)
+		for {
+			arg, err := p.parseSum()
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, arg)
+			if _, ok := p.accept(","); !ok {
+				break
+			}
+		}
+		if _, ok := p.accept(")"); !ok {
+			return 0, fmt.Errorf("missing closing parenthesis")
+		}
+		return f(args)
+	}
+
+	if value, ok := p.vars[name]; ok {
+		return value, nil
+	}
+	switch name {
+	case "pi":
+		return math.Pi, nil
+	case "e":
+		return math.E, nil
+	}
+	return 0, fmt.Errorf("unknown variable %q", name)
+}